@@ -0,0 +1,193 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	operatorv1 "github.com/openshift/api/operator/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// IngressNamespace is the namespace where the default IngressController's serving certificate lives.
+	IngressNamespace = "openshift-ingress"
+	// ConfigNamespace is the namespace where cluster-wide trust bundles and ComponentRoute certs live.
+	ConfigNamespace = "openshift-config"
+)
+
+// SecretReference refers to a Secret by name and namespace.
+type SecretReference struct {
+	// Name is the name of the Secret.
+	Name string `json:"name"`
+	// Namespace is the namespace of the Secret.
+	Namespace string `json:"namespace"`
+}
+
+// ClusterRelocationSpec defines the desired state of ClusterRelocation
+type ClusterRelocationSpec struct {
+	// Domain is the new base domain to apply to the cluster's Ingress and API server.
+	Domain string `json:"domain,omitempty"`
+
+	// PullSecretRef is a reference to a Secret containing a new pull secret for the cluster.
+	// +optional
+	PullSecretRef *SecretReference `json:"pullSecretRef,omitempty"`
+
+	// IngressCertRef is a reference to a kubernetes.io/tls Secret to use as the Ingress
+	// wildcard certificate. If unset, a self-signed certificate is generated, unless
+	// IngressCertIssuerRef is set.
+	// +optional
+	IngressCertRef *SecretReference `json:"ingressCertRef,omitempty"`
+
+	// IngressCertIssuerRef requests the Ingress wildcard certificate from a cert-manager Issuer
+	// or ClusterIssuer instead of generating a self-signed certificate. Ignored if IngressCertRef
+	// is set.
+	// +optional
+	IngressCertIssuerRef *IssuerReference `json:"ingressCertIssuerRef,omitempty"`
+
+	// APIServerCertRef is a reference to a kubernetes.io/tls Secret to use as the API server
+	// serving certificate. If unset, a self-signed certificate is generated.
+	// +optional
+	APIServerCertRef *SecretReference `json:"apiServerCertRef,omitempty"`
+
+	// ComponentRouteOverrides lets individual console, downloads, oauth-openshift, or other
+	// ComponentRoutes use a distinct serving certificate instead of the cluster-wide Ingress
+	// certificate, and lets additional user-defined ComponentRoutes be added.
+	// +optional
+	ComponentRouteOverrides []ComponentRouteOverride `json:"componentRouteOverrides,omitempty"`
+
+	// RouteResetExclusions lists Routes that the route-reset controller should never delete,
+	// even if their current hostname doesn't match Domain. Extends the operator's built-in
+	// exclusions (Routes in openshift-console/openshift-authentication, which are reconciled via
+	// the cluster Ingress's ComponentRoutes instead, and the open-cluster-management-agent-addon
+	// workaround).
+	// +optional
+	RouteResetExclusions []NamespacedNameSelector `json:"routeResetExclusions,omitempty"`
+
+	// IngressPublishingStrategy, if set, is applied to the default IngressController's
+	// EndpointPublishingStrategy. This is useful for relocated or disconnected clusters that need
+	// to switch their default ingress to an internal load balancer or off of a LoadBalancer
+	// Service entirely, mirroring the private-HCP pattern hypershift uses for clusters with no
+	// public DNS. The IngressController's original EndpointPublishingStrategy is restored on
+	// Cleanup.
+	// +optional
+	// +kubebuilder:validation:Enum=External;Internal;NodePortService;HostNetwork
+	IngressPublishingStrategy IngressPublishingStrategy `json:"ingressPublishingStrategy,omitempty"`
+
+	// InternalLoadBalancerScope overrides the load balancer Scope that IngressPublishingStrategy
+	// External or Internal would otherwise default to, for cloud providers where the desired
+	// scope doesn't match the strategy's default (e.g. an External strategy fronted by an
+	// internal-only load balancer behind a separate public proxy).
+	// +optional
+	InternalLoadBalancerScope *operatorv1.LoadBalancerScope `json:"internalLoadBalancerScope,omitempty"`
+}
+
+// IngressPublishingStrategy selects how the default IngressController's endpoints are published,
+// mirroring operatorv1.EndpointPublishingStrategyType's options.
+type IngressPublishingStrategy string
+
+const (
+	// IngressPublishingStrategyExternal publishes the default IngressController via a
+	// LoadBalancer Service with an external-facing load balancer.
+	IngressPublishingStrategyExternal IngressPublishingStrategy = "External"
+	// IngressPublishingStrategyInternal publishes the default IngressController via a
+	// LoadBalancer Service with an internal-only load balancer and no public DNS.
+	IngressPublishingStrategyInternal IngressPublishingStrategy = "Internal"
+	// IngressPublishingStrategyNodePortService publishes the default IngressController via a
+	// NodePort Service.
+	IngressPublishingStrategyNodePortService IngressPublishingStrategy = "NodePortService"
+	// IngressPublishingStrategyHostNetwork publishes the default IngressController directly on
+	// node ports 80/443 using host networking.
+	IngressPublishingStrategyHostNetwork IngressPublishingStrategy = "HostNetwork"
+)
+
+// IssuerReference identifies a cert-manager Issuer or ClusterIssuer to request the Ingress
+// wildcard certificate from, mirroring cert-manager's own cmmeta.IssuerReference shape.
+type IssuerReference struct {
+	// Name is the name of the Issuer or ClusterIssuer.
+	Name string `json:"name"`
+	// Kind is the kind of the issuer, "Issuer" or "ClusterIssuer". Defaults to "Issuer".
+	// +optional
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	Kind string `json:"kind,omitempty"`
+	// Group is the API group of the issuer. Defaults to cert-manager.io.
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// WaitTimeout bounds how long Reconcile waits for cert-manager to issue the certificate
+	// before failing. Defaults to 5 minutes.
+	// +optional
+	WaitTimeout *metav1.Duration `json:"waitTimeout,omitempty"`
+}
+
+// NamespacedNameSelector matches Routes (or other namespaced objects) by namespace, label
+// selector, or both. An empty selector matches everything.
+type NamespacedNameSelector struct {
+	// Namespace, if set, restricts matches to this namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// LabelSelector, if set, restricts matches to objects with these labels.
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+}
+
+// ComponentRouteOverride customizes a single entry of the cluster Ingress's
+// Spec.ComponentRoutes, matching the shape cluster-authentication-operator uses for custom
+// OAuth route certs.
+type ComponentRouteOverride struct {
+	// Name is the name of the ComponentRoute to override, e.g. "console" or "oauth-openshift".
+	// If it doesn't match one of the operator's default ComponentRoutes, a new entry is added.
+	Name string `json:"name"`
+	// Namespace is the namespace of the component serving this route.
+	Namespace string `json:"namespace"`
+	// Hostname is the hostname to serve this route on.
+	Hostname string `json:"hostname"`
+	// ServingCertRef is a reference to a kubernetes.io/tls Secret (or PKCS#12/JKS bundle) to use
+	// as this route's serving certificate. If unset, the default Ingress certificate is used.
+	// +optional
+	ServingCertRef *SecretReference `json:"servingCertRef,omitempty"`
+}
+
+// ClusterRelocationStatus defines the observed state of ClusterRelocation
+type ClusterRelocationStatus struct {
+	// Conditions represent the latest available observations of the relocation's state.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// ClusterRelocation is the Schema for the clusterrelocations API
+type ClusterRelocation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterRelocationSpec   `json:"spec,omitempty"`
+	Status ClusterRelocationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ClusterRelocationList contains a list of ClusterRelocation
+type ClusterRelocationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterRelocation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterRelocation{}, &ClusterRelocationList{})
+}
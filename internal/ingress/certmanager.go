@@ -0,0 +1,143 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	rhsysenggithubiov1beta1 "github.com/RHsyseng/cluster-relocation-operator/api/v1beta1"
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/go-logr/logr"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// issuedIngressCertName is the cert-manager Certificate requested for the Ingress wildcard
+	// domain when IngressCertIssuerRef is set.
+	issuedIngressCertName = "ingress-acme-cert"
+	// issuedIngressCertSecretName is the Secret cert-manager writes the issued certificate to, in
+	// the same namespace (openshift-ingress) and under the same name the IngressCertRef path uses
+	// for its copy, so the rest of Reconcile doesn't need to special-case this mode.
+	issuedIngressCertSecretName = "copied-ingress-secret"
+
+	// defaultIssuerWaitTimeout bounds how long Reconcile waits for cert-manager to issue the
+	// certificate when IngressCertIssuerRef.WaitTimeout is unset.
+	defaultIssuerWaitTimeout = 5 * time.Minute
+
+	// certificateRecheckInterval is the requeue delay Reconcile uses while waiting for
+	// cert-manager to report the Ingress ACME Certificate Ready.
+	certificateRecheckInterval = 15 * time.Second
+
+	// certificateRequestNameLabel is set by cert-manager on every CertificateRequest it creates
+	// for a Certificate, naming the owning Certificate.
+	certificateRequestNameLabel = "cert-manager.io/certificate-name"
+
+	// IngressCertificateIssuerFailing is the ClusterRelocation status condition type set when
+	// cert-manager fails to issue the Ingress wildcard certificate.
+	IngressCertificateIssuerFailing = "IngressCertificateIssuerFailing"
+)
+
+// ensureIssuedIngressCert requests the Ingress wildcard certificate from relocation's
+// IngressCertIssuerRef, returning whether cert-manager has reported it Ready yet. It never
+// blocks: the caller is expected to requeue and call again while ready is false, exactly like the
+// self-signed rotation path signals its own next reconcile via a requeue duration. Once ready is
+// true, issuedIngressCertSecretName is guaranteed to exist in openshift-ingress.
+func ensureIssuedIngressCert(ctx context.Context, c client.Client, scheme *runtime.Scheme, relocation *rhsysenggithubiov1beta1.ClusterRelocation, logger logr.Logger) (bool, error) {
+	issuerRef := relocation.Spec.IngressCertIssuerRef
+
+	cert := &certmanagerv1.Certificate{ObjectMeta: metav1.ObjectMeta{Name: issuedIngressCertName, Namespace: rhsysenggithubiov1beta1.IngressNamespace}}
+	op, err := controllerutil.CreateOrPatch(ctx, c, cert, func() error {
+		cert.Spec.SecretName = issuedIngressCertSecretName
+		cert.Spec.DNSNames = []string{fmt.Sprintf("*.apps.%s", relocation.Spec.Domain)}
+		cert.Spec.IssuerRef = cmmeta.IssuerReference{
+			Name:  issuerRef.Name,
+			Kind:  issuerRef.Kind,
+			Group: issuerRef.Group,
+		}
+		return controllerutil.SetControllerReference(relocation, cert, scheme)
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to reconcile Ingress ACME Certificate: %w", err)
+	}
+	if op != controllerutil.OperationResultNone {
+		logger.Info("Ingress ACME Certificate modified", "OperationResult", op)
+	}
+
+	for i := range cert.Status.Conditions {
+		cond := cert.Status.Conditions[i]
+		if cond.Type != certmanagerv1.CertificateConditionReady {
+			continue
+		}
+		if cond.Status == cmmeta.ConditionTrue {
+			return true, nil
+		}
+		if issuerWaitExpired(cert, &cond, issuerRef) {
+			return false, fmt.Errorf("timed out waiting for Ingress ACME Certificate to become Ready: %s: %s", cond.Reason, cond.Message)
+		}
+		return false, nil
+	}
+
+	if issuerWaitExpired(cert, nil, issuerRef) {
+		return false, fmt.Errorf("timed out waiting for Ingress ACME Certificate to become Ready: no Ready condition reported yet")
+	}
+	return false, nil
+}
+
+// issuerWaitExpired reports whether cert has been waiting on cert-manager for longer than
+// issuerRef's WaitTimeout (or defaultIssuerWaitTimeout if unset). When readyCond is non-nil, the
+// wait is measured from the condition's own LastTransitionTime rather than cert.CreationTimestamp:
+// cert-manager flips Ready to False (reason Renewing) on every routine renewal for the life of the
+// Certificate, not just at initial issuance, so timing from CreationTimestamp would treat every
+// later renewal as a fresh timeout. With no Ready condition reported yet, the wait is measured from
+// the Certificate's creation, since there's no transition time to anchor to.
+func issuerWaitExpired(cert *certmanagerv1.Certificate, readyCond *certmanagerv1.CertificateCondition, issuerRef *rhsysenggithubiov1beta1.IssuerReference) bool {
+	timeout := defaultIssuerWaitTimeout
+	if issuerRef.WaitTimeout != nil {
+		timeout = issuerRef.WaitTimeout.Duration
+	}
+	since := cert.CreationTimestamp.Time
+	if readyCond != nil && readyCond.LastTransitionTime != nil {
+		since = readyCond.LastTransitionTime.Time
+	}
+	return time.Since(since) > timeout
+}
+
+// surfaceCertificateRequestFailures sets relocation's IngressCertificateIssuerFailing condition
+// from the most recent failed CertificateRequest cert-manager created for the Ingress ACME
+// Certificate, or clears it if none are currently failing.
+func surfaceCertificateRequestFailures(ctx context.Context, c client.Client, relocation *rhsysenggithubiov1beta1.ClusterRelocation) error {
+	requests := &certmanagerv1.CertificateRequestList{}
+	if err := c.List(ctx, requests, client.InNamespace(rhsysenggithubiov1beta1.IngressNamespace), client.MatchingLabels{certificateRequestNameLabel: issuedIngressCertName}); err != nil {
+		return fmt.Errorf("failed to list CertificateRequests for Ingress ACME Certificate: %w", err)
+	}
+
+	// c.List doesn't guarantee any particular order; sort newest-first so the loop below picks the
+	// most recent failure rather than whatever the apiserver happened to return first.
+	sort.Slice(requests.Items, func(i, j int) bool {
+		return requests.Items[i].CreationTimestamp.After(requests.Items[j].CreationTimestamp.Time)
+	})
+
+	for i := range requests.Items {
+		request := &requests.Items[i]
+		for _, cond := range request.Status.Conditions {
+			if cond.Type == certmanagerv1.CertificateRequestConditionReady && cond.Status == cmmeta.ConditionFalse && cond.Reason == certmanagerv1.CertificateRequestReasonFailed {
+				apimeta.SetStatusCondition(&relocation.Status.Conditions, metav1.Condition{
+					Type:    IngressCertificateIssuerFailing,
+					Status:  metav1.ConditionTrue,
+					Reason:  cond.Reason,
+					Message: fmt.Sprintf("CertificateRequest %s failed: %s", request.Name, cond.Message),
+				})
+				return nil
+			}
+		}
+	}
+
+	apimeta.RemoveStatusCondition(&relocation.Status.Conditions, IngressCertificateIssuerFailing)
+	return nil
+}
@@ -0,0 +1,299 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	rhsysenggithubiov1beta1 "github.com/RHsyseng/cluster-relocation-operator/api/v1beta1"
+	certmanagerv1 "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	"github.com/go-logr/logr"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newCertManagerTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	if err := rhsysenggithubiov1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	if err := certmanagerv1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	return scheme
+}
+
+func TestEnsureIssuedIngressCertWaitsForReady(t *testing.T) {
+	scheme := newCertManagerTestScheme(t)
+	relocation := &rhsysenggithubiov1beta1.ClusterRelocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: rhsysenggithubiov1beta1.ClusterRelocationSpec{
+			Domain: "example.com",
+			IngressCertIssuerRef: &rhsysenggithubiov1beta1.IssuerReference{
+				Name:        "letsencrypt",
+				Kind:        "ClusterIssuer",
+				WaitTimeout: &metav1.Duration{Duration: time.Minute},
+			},
+		},
+	}
+	// Pre-seed the Certificate with a realistic CreationTimestamp, since (unlike a real
+	// apiserver) the fake client doesn't stamp one in on Create.
+	existing := &certmanagerv1.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              issuedIngressCertName,
+			Namespace:         rhsysenggithubiov1beta1.IngressNamespace,
+			CreationTimestamp: metav1.Now(),
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).WithStatusSubresource(&certmanagerv1.Certificate{}).Build()
+
+	// With no Ready condition reported yet and well within WaitTimeout, ensureIssuedIngressCert
+	// must return promptly with ready=false rather than blocking.
+	ready, err := ensureIssuedIngressCert(context.Background(), c, scheme, relocation, logr.Discard())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ready {
+		t.Fatalf("expected ready=false before cert-manager reports the Certificate Ready")
+	}
+
+	cert := &certmanagerv1.Certificate{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: issuedIngressCertName, Namespace: rhsysenggithubiov1beta1.IngressNamespace}, cert); err != nil {
+		t.Fatalf("expected the Certificate to have been created: %v", err)
+	}
+	if cert.Spec.SecretName != issuedIngressCertSecretName {
+		t.Errorf("got SecretName %q, want %q", cert.Spec.SecretName, issuedIngressCertSecretName)
+	}
+	if len(cert.Spec.DNSNames) != 1 || cert.Spec.DNSNames[0] != "*.apps.example.com" {
+		t.Errorf("got DNSNames %v, want [*.apps.example.com]", cert.Spec.DNSNames)
+	}
+	if cert.Spec.IssuerRef.Name != "letsencrypt" || cert.Spec.IssuerRef.Kind != "ClusterIssuer" {
+		t.Errorf("got IssuerRef %+v, want Name=letsencrypt Kind=ClusterIssuer", cert.Spec.IssuerRef)
+	}
+
+	// Now mark it Ready and confirm ensureIssuedIngressCert reports ready=true.
+	cert.Status.Conditions = []certmanagerv1.CertificateCondition{
+		{Type: certmanagerv1.CertificateConditionReady, Status: cmmeta.ConditionTrue},
+	}
+	if err := c.Status().Update(context.Background(), cert); err != nil {
+		t.Fatalf("failed to mark Certificate Ready: %v", err)
+	}
+
+	ready, err = ensureIssuedIngressCert(context.Background(), c, scheme, relocation, logr.Discard())
+	if err != nil {
+		t.Fatalf("unexpected error once the Certificate is Ready: %v", err)
+	}
+	if !ready {
+		t.Fatalf("expected ready=true once cert-manager reports the Certificate Ready")
+	}
+}
+
+func TestEnsureIssuedIngressCertTimesOut(t *testing.T) {
+	scheme := newCertManagerTestScheme(t)
+	relocation := &rhsysenggithubiov1beta1.ClusterRelocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: rhsysenggithubiov1beta1.ClusterRelocationSpec{
+			Domain: "example.com",
+			IngressCertIssuerRef: &rhsysenggithubiov1beta1.IssuerReference{
+				Name:        "letsencrypt",
+				Kind:        "ClusterIssuer",
+				WaitTimeout: &metav1.Duration{Duration: time.Minute},
+			},
+		},
+	}
+	// Pre-seed the Certificate as if it had been created well over WaitTimeout ago, with no
+	// Ready condition reported yet.
+	existing := &certmanagerv1.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              issuedIngressCertName,
+			Namespace:         rhsysenggithubiov1beta1.IngressNamespace,
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).WithStatusSubresource(&certmanagerv1.Certificate{}).Build()
+
+	if _, err := ensureIssuedIngressCert(context.Background(), c, scheme, relocation, logr.Discard()); err == nil {
+		t.Fatalf("expected a timeout error once WaitTimeout has elapsed with no Ready condition")
+	}
+}
+
+func TestEnsureIssuedIngressCertRenewalNotTreatedAsTimeout(t *testing.T) {
+	scheme := newCertManagerTestScheme(t)
+	relocation := &rhsysenggithubiov1beta1.ClusterRelocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: rhsysenggithubiov1beta1.ClusterRelocationSpec{
+			Domain: "example.com",
+			IngressCertIssuerRef: &rhsysenggithubiov1beta1.IssuerReference{
+				Name:        "letsencrypt",
+				Kind:        "ClusterIssuer",
+				WaitTimeout: &metav1.Duration{Duration: time.Minute},
+			},
+		},
+	}
+	// A long-lived Certificate, well past WaitTimeout since creation, that cert-manager has just
+	// flipped to Ready=False for a routine renewal (reason Renewing). This must not be treated as
+	// a timeout: the renewal only just started.
+	existing := &certmanagerv1.Certificate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              issuedIngressCertName,
+			Namespace:         rhsysenggithubiov1beta1.IngressNamespace,
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-24 * time.Hour)),
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).WithStatusSubresource(&certmanagerv1.Certificate{}).Build()
+
+	cert := &certmanagerv1.Certificate{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: issuedIngressCertName, Namespace: rhsysenggithubiov1beta1.IngressNamespace}, cert); err != nil {
+		t.Fatalf("failed to fetch seeded Certificate: %v", err)
+	}
+	cert.Status.Conditions = []certmanagerv1.CertificateCondition{
+		{
+			Type:               certmanagerv1.CertificateConditionReady,
+			Status:             cmmeta.ConditionFalse,
+			Reason:             "Renewing",
+			LastTransitionTime: &metav1.Time{Time: time.Now()},
+		},
+	}
+	if err := c.Status().Update(context.Background(), cert); err != nil {
+		t.Fatalf("failed to seed renewing Certificate status: %v", err)
+	}
+
+	ready, err := ensureIssuedIngressCert(context.Background(), c, scheme, relocation, logr.Discard())
+	if err != nil {
+		t.Fatalf("unexpected error: a fresh renewal must not be treated as a timeout just because the Certificate is old: %v", err)
+	}
+	if ready {
+		t.Fatalf("expected ready=false while the renewed Certificate is still in the Ready=False window")
+	}
+}
+
+func TestSurfaceCertificateRequestFailures(t *testing.T) {
+	scheme := newCertManagerTestScheme(t)
+	relocation := &rhsysenggithubiov1beta1.ClusterRelocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       rhsysenggithubiov1beta1.ClusterRelocationSpec{Domain: "example.com"},
+	}
+	failingRequest := &certmanagerv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress-acme-cert-1",
+			Namespace: rhsysenggithubiov1beta1.IngressNamespace,
+			Labels:    map[string]string{certificateRequestNameLabel: issuedIngressCertName},
+		},
+		Status: certmanagerv1.CertificateRequestStatus{
+			Conditions: []certmanagerv1.CertificateRequestCondition{
+				{
+					Type:    certmanagerv1.CertificateRequestConditionReady,
+					Status:  cmmeta.ConditionFalse,
+					Reason:  certmanagerv1.CertificateRequestReasonFailed,
+					Message: "authorization failed",
+				},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(failingRequest).WithStatusSubresource(failingRequest).Build()
+	if err := c.Status().Update(context.Background(), failingRequest); err != nil {
+		t.Fatalf("failed to seed CertificateRequest status: %v", err)
+	}
+
+	if err := surfaceCertificateRequestFailures(context.Background(), c, relocation); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found := false
+	for _, cond := range relocation.Status.Conditions {
+		if cond.Type == IngressCertificateIssuerFailing {
+			found = true
+			if cond.Status != metav1.ConditionTrue {
+				t.Errorf("expected %s condition to be True, got %s", IngressCertificateIssuerFailing, cond.Status)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("expected a %s condition to be set", IngressCertificateIssuerFailing)
+	}
+
+	// Once the CertificateRequest is gone, the condition should clear.
+	if err := c.Delete(context.Background(), failingRequest); err != nil {
+		t.Fatalf("failed to delete CertificateRequest: %v", err)
+	}
+	if err := surfaceCertificateRequestFailures(context.Background(), c, relocation); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, cond := range relocation.Status.Conditions {
+		if cond.Type == IngressCertificateIssuerFailing {
+			t.Errorf("expected %s condition to be cleared once the CertificateRequest is gone", IngressCertificateIssuerFailing)
+		}
+	}
+}
+
+func TestSurfaceCertificateRequestFailuresUsesMostRecent(t *testing.T) {
+	scheme := newCertManagerTestScheme(t)
+	relocation := &rhsysenggithubiov1beta1.ClusterRelocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       rhsysenggithubiov1beta1.ClusterRelocationSpec{Domain: "example.com"},
+	}
+	stale := &certmanagerv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "ingress-acme-cert-1",
+			Namespace:         rhsysenggithubiov1beta1.IngressNamespace,
+			Labels:            map[string]string{certificateRequestNameLabel: issuedIngressCertName},
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+		},
+		Status: certmanagerv1.CertificateRequestStatus{
+			Conditions: []certmanagerv1.CertificateRequestCondition{
+				{
+					Type:    certmanagerv1.CertificateRequestConditionReady,
+					Status:  cmmeta.ConditionFalse,
+					Reason:  certmanagerv1.CertificateRequestReasonFailed,
+					Message: "stale authorization failure",
+				},
+			},
+		},
+	}
+	recent := &certmanagerv1.CertificateRequest{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "ingress-acme-cert-2",
+			Namespace:         rhsysenggithubiov1beta1.IngressNamespace,
+			Labels:            map[string]string{certificateRequestNameLabel: issuedIngressCertName},
+			CreationTimestamp: metav1.Now(),
+		},
+		Status: certmanagerv1.CertificateRequestStatus{
+			Conditions: []certmanagerv1.CertificateRequestCondition{
+				{
+					Type:    certmanagerv1.CertificateRequestConditionReady,
+					Status:  cmmeta.ConditionFalse,
+					Reason:  certmanagerv1.CertificateRequestReasonFailed,
+					Message: "recent authorization failure",
+				},
+			},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(stale, recent).WithStatusSubresource(stale, recent).Build()
+	if err := c.Status().Update(context.Background(), stale); err != nil {
+		t.Fatalf("failed to seed stale CertificateRequest status: %v", err)
+	}
+	if err := c.Status().Update(context.Background(), recent); err != nil {
+		t.Fatalf("failed to seed recent CertificateRequest status: %v", err)
+	}
+
+	if err := surfaceCertificateRequestFailures(context.Background(), c, relocation); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cond := apimeta.FindStatusCondition(relocation.Status.Conditions, IngressCertificateIssuerFailing)
+	if cond == nil {
+		t.Fatalf("expected a %s condition to be set", IngressCertificateIssuerFailing)
+	}
+	if cond.Message != "CertificateRequest ingress-acme-cert-2 failed: recent authorization failure" {
+		t.Errorf("expected the most recent CertificateRequest's failure to be surfaced, got message %q", cond.Message)
+	}
+}
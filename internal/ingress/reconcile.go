@@ -2,16 +2,15 @@ package ingress
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"strings"
+	"time"
 
 	rhsysenggithubiov1beta1 "github.com/RHsyseng/cluster-relocation-operator/api/v1beta1"
 	secrets "github.com/RHsyseng/cluster-relocation-operator/internal/secrets"
-	"github.com/RHsyseng/cluster-relocation-operator/internal/util"
 	"github.com/go-logr/logr"
 	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
-	routev1 "github.com/openshift/api/route/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -19,58 +18,65 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
+// originalPublishingStrategyAnnotation records the default IngressController's
+// EndpointPublishingStrategy as it existed before this operator first changed it, so Cleanup can
+// restore it exactly.
+const originalPublishingStrategyAnnotation = "cluster-relocation.rhsyseng.io/original-publishing-strategy"
+
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=create;update;get;list;watch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=create;update;get;list;watch
 //+kubebuilder:rbac:groups=operator.openshift.io,resources=ingresscontrollers,verbs=patch;get;list;watch
 //+kubebuilder:rbac:groups=config.openshift.io,resources=ingresses,verbs=patch;get;list;watch
 //+kubebuilder:rbac:groups=route.openshift.io,resources=routes,verbs=list;delete;watch
+//+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=create;update;patch;get;list;watch
+//+kubebuilder:rbac:groups=cert-manager.io,resources=certificaterequests,verbs=get;list;watch
 
-func Reconcile(ctx context.Context, c client.Client, scheme *runtime.Scheme, relocation *rhsysenggithubiov1beta1.ClusterRelocation, logger logr.Logger) error {
+// Reconcile configures Ingress certificates and domain aliases for the new domain. The returned
+// duration, when non-zero, is the time after which Reconcile should be called again: either to
+// rotate a managed certificate ahead of its expiry, or to check again whether cert-manager has
+// issued the IngressCertIssuerRef certificate yet. It is zero when no requeue is needed.
+func Reconcile(ctx context.Context, c client.Client, scheme *runtime.Scheme, relocation *rhsysenggithubiov1beta1.ClusterRelocation, logger logr.Logger) (time.Duration, error) {
 	// Configure certificates with the new domain name for the ingress
 	var origSecretName string
 	var origSecretNamespace string
-	if relocation.Spec.IngressCertRef == nil {
-		// If they haven't specified an IngressCertRef, we generate a self-signed certificate for them
+	var requeueAfter time.Duration
+	if relocation.Spec.IngressCertRef == nil && relocation.Spec.IngressCertIssuerRef == nil {
+		// If they haven't specified an IngressCertRef or IngressCertIssuerRef, we maintain a
+		// long-lived signing CA plus a short-lived leaf certificate for them, rotating the leaf
+		// as it approaches expiry or the domain changes, mirroring library-go's certrotation
+		// package.
 		origSecretName = "generated-ingress-secret"
 		origSecretNamespace = rhsysenggithubiov1beta1.IngressNamespace
-		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: origSecretName, Namespace: origSecretNamespace}}
-
-		op, err := controllerutil.CreateOrUpdate(ctx, c, secret, func() error {
-			_, ok := secret.Data[corev1.TLSCertKey]
-			// Check if the secret already has a key set
-			// If there is no key set, generate one
-			// This is done so that we don't generate a new certificate each time Reconcile runs
-			if !ok {
-				logger.Info("generating new TLS cert for Ingresses")
-				var err error
-				secret.Data, err = secrets.GenerateTLSKeyPair(relocation.Spec.Domain, "*.apps")
-				if err != nil {
-					return err
-				}
-			} else {
-				logger.Info("TLS cert already exists for Ingresses")
-				commonName, err := secrets.GetCertCommonName(secret.Data[corev1.TLSCertKey])
-				if err != nil {
-					return err
-				}
-				if commonName != fmt.Sprintf("*.apps.%s", relocation.Spec.Domain) {
-					logger.Info("Domain name has changed, generating new TLS certificate for Ingresses")
-					var err error
-					secret.Data, err = secrets.GenerateTLSKeyPair(relocation.Spec.Domain, "*.apps")
-					if err != nil {
-						return err
-					}
-				}
-			}
-			secret.Type = corev1.SecretTypeTLS
-			// Set the controller as the owner so that the secret is deleted along with the CR
-			return controllerutil.SetControllerReference(relocation, secret, scheme)
-		})
+
+		signingRotation := secrets.SigningRotation{
+			Name:      secrets.IngressCASecretName,
+			Namespace: origSecretNamespace,
+		}
+		ca, op, err := signingRotation.EnsureSigningCertKeyPair(ctx, c, relocation, scheme)
+		if err != nil {
+			return 0, fmt.Errorf("failed to reconcile Ingress signing CA: %w", err)
+		}
+		if op != controllerutil.OperationResultNone {
+			logger.Info("Ingress signing CA modified", "OperationResult", op)
+		}
+
+		if _, err := secrets.EnsureIngressCAConfigMap(ctx, c, relocation, scheme, ca); err != nil {
+			return 0, fmt.Errorf("failed to publish Ingress CA configmap: %w", err)
+		}
+
+		targetRotation := secrets.TargetRotation{
+			Name:      origSecretName,
+			Namespace: origSecretNamespace,
+			Hostnames: []string{fmt.Sprintf("*.apps.%s", relocation.Spec.Domain)},
+		}
+		leaf, op, err := targetRotation.EnsureTargetCertKeyPair(ctx, c, relocation, scheme, ca)
 		if err != nil {
-			return err
+			return 0, fmt.Errorf("failed to reconcile Ingress leaf certificate: %w", err)
 		}
 		if op != controllerutil.OperationResultNone {
-			logger.Info("Self-signed Ingress TLS cert modified", "OperationResult", op)
+			logger.Info("Ingress leaf TLS cert modified", "OperationResult", op)
 		}
+		requeueAfter = secrets.EarliestRefresh(ca, leaf)
 
 		secretName := origSecretName
 		copySettings := secrets.SecretCopySettings{
@@ -81,17 +87,17 @@ func Reconcile(ctx context.Context, c client.Client, scheme *runtime.Scheme, rel
 		}
 		op, err = secrets.CopySecret(ctx, c, relocation, scheme, origSecretName, origSecretNamespace, secretName, rhsysenggithubiov1beta1.ConfigNamespace, copySettings)
 		if err != nil {
-			return err
+			return 0, err
 		}
 		if op != controllerutil.OperationResultNone {
 			logger.Info(fmt.Sprintf("Generated Ingress cert copied to %s", rhsysenggithubiov1beta1.ConfigNamespace), "OperationResult", op)
 		}
-	} else {
+	} else if relocation.Spec.IngressCertRef != nil {
 		if relocation.Spec.IngressCertRef.Name == "" || relocation.Spec.IngressCertRef.Namespace == "" {
-			return fmt.Errorf("must specify secret name and namespace")
+			return 0, fmt.Errorf("must specify secret name and namespace")
 		}
-		if err := secrets.ValidateSecretType(ctx, c, relocation.Spec.IngressCertRef, corev1.SecretTypeTLS); err != nil {
-			return err
+		if err := secrets.ValidateCertSecret(ctx, c, relocation.Spec.IngressCertRef); err != nil {
+			return 0, err
 		}
 
 		origSecretName = relocation.Spec.IngressCertRef.Name
@@ -113,7 +119,7 @@ func Reconcile(ctx context.Context, c client.Client, scheme *runtime.Scheme, rel
 		}
 		op, err := secrets.CopySecret(ctx, c, relocation, scheme, origSecretName, origSecretNamespace, secretName, rhsysenggithubiov1beta1.IngressNamespace, copySettings)
 		if err != nil {
-			return err
+			return 0, err
 		}
 		if op != controllerutil.OperationResultNone {
 			logger.Info(fmt.Sprintf("User provided Ingress cert copied to %s", rhsysenggithubiov1beta1.IngressNamespace), "OperationResult", op)
@@ -122,12 +128,48 @@ func Reconcile(ctx context.Context, c client.Client, scheme *runtime.Scheme, rel
 		// Copy the secret into the openshift-config namespace
 		op, err = secrets.CopySecret(ctx, c, relocation, scheme, origSecretName, origSecretNamespace, secretName, rhsysenggithubiov1beta1.ConfigNamespace, copySettings)
 		if err != nil {
-			return err
+			return 0, err
 		}
 		if op != controllerutil.OperationResultNone {
 			logger.Info(fmt.Sprintf("User provided Ingress cert copied to %s", rhsysenggithubiov1beta1.ConfigNamespace), "OperationResult", op)
 		}
 		origSecretName = secretName
+	} else {
+		// IngressCertIssuerRef is set: request the certificate from cert-manager instead of
+		// generating one ourselves or using a user-provided Secret.
+		if err := surfaceCertificateRequestFailures(ctx, c, relocation); err != nil {
+			return 0, err
+		}
+		ready, err := ensureIssuedIngressCert(ctx, c, scheme, relocation, logger)
+		if err != nil {
+			return 0, err
+		}
+		if !ready {
+			// cert-manager hasn't issued the certificate yet; requeue instead of blocking the
+			// Reconcile call, mirroring how the self-signed rotation path above signals its next
+			// reconcile via a requeue duration rather than waiting in place.
+			return certificateRecheckInterval, nil
+		}
+
+		origSecretName = issuedIngressCertSecretName
+		origSecretNamespace = rhsysenggithubiov1beta1.IngressNamespace
+
+		// cert-manager writes the Secret directly into openshift-ingress; we add non-controller
+		// ownership so we're notified (and re-reconcile) when it rotates the certificate, then
+		// copy it into openshift-config under the same name.
+		copySettings := secrets.SecretCopySettings{
+			OwnOriginal:                  true,
+			OriginalOwnedByController:    false,
+			OwnDestination:               true,
+			DestinationOwnedByController: true,
+		}
+		op, err := secrets.CopySecret(ctx, c, relocation, scheme, origSecretName, origSecretNamespace, origSecretName, rhsysenggithubiov1beta1.ConfigNamespace, copySettings)
+		if err != nil {
+			return 0, err
+		}
+		if op != controllerutil.OperationResultNone {
+			logger.Info(fmt.Sprintf("cert-manager issued Ingress cert copied to %s", rhsysenggithubiov1beta1.ConfigNamespace), "OperationResult", op)
+		}
 	}
 
 	// Define the IngressController's namespace and name
@@ -137,55 +179,170 @@ func Reconcile(ctx context.Context, c client.Client, scheme *runtime.Scheme, rel
 	ingressController := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
 	op, err := controllerutil.CreateOrPatch(ctx, c, ingressController, func() error {
 		ingressController.Spec.DefaultCertificate = &corev1.LocalObjectReference{Name: origSecretName}
+		if relocation.Spec.IngressPublishingStrategy != "" {
+			if err := applyIngressPublishingStrategy(ingressController, relocation.Spec.IngressPublishingStrategy, relocation.Spec.InternalLoadBalancerScope); err != nil {
+				return err
+			}
+		}
 		return nil
 	})
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if op != controllerutil.OperationResultNone {
 		logger.Info("IngressController modified", "OperationResult", op)
 	}
 
-	ingress := &configv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}
-	op, err = controllerutil.CreateOrPatch(ctx, c, ingress, func() error {
-		ingress.Spec.AppsDomain = fmt.Sprintf("apps.%s", relocation.Spec.Domain)
-		ingress.Spec.ComponentRoutes = []configv1.ComponentRouteSpec{
-			{
-				Hostname:  configv1.Hostname(fmt.Sprintf("console-openshift-console.apps.%s", relocation.Spec.Domain)),
-				Name:      "console",
-				Namespace: "openshift-console",
-				ServingCertKeyPairSecret: configv1.SecretNameReference{
-					Name: origSecretName,
-				},
+	componentRoutes := []configv1.ComponentRouteSpec{
+		{
+			Hostname:  configv1.Hostname(fmt.Sprintf("console-openshift-console.apps.%s", relocation.Spec.Domain)),
+			Name:      "console",
+			Namespace: "openshift-console",
+			ServingCertKeyPairSecret: configv1.SecretNameReference{
+				Name: origSecretName,
 			},
-			{
-				Hostname:  configv1.Hostname(fmt.Sprintf("downloads-openshift-console.apps.%s", relocation.Spec.Domain)),
-				Name:      "downloads",
-				Namespace: "openshift-console",
-				ServingCertKeyPairSecret: configv1.SecretNameReference{
-					Name: origSecretName,
-				},
+		},
+		{
+			Hostname:  configv1.Hostname(fmt.Sprintf("downloads-openshift-console.apps.%s", relocation.Spec.Domain)),
+			Name:      "downloads",
+			Namespace: "openshift-console",
+			ServingCertKeyPairSecret: configv1.SecretNameReference{
+				Name: origSecretName,
 			},
-			{
-				Hostname:  configv1.Hostname(fmt.Sprintf("oauth-openshift.apps.%s", relocation.Spec.Domain)),
-				Name:      "oauth-openshift",
-				Namespace: "openshift-authentication",
-				ServingCertKeyPairSecret: configv1.SecretNameReference{
-					Name: origSecretName,
-				},
+		},
+		{
+			Hostname:  configv1.Hostname(fmt.Sprintf("oauth-openshift.apps.%s", relocation.Spec.Domain)),
+			Name:      "oauth-openshift",
+			Namespace: "openshift-authentication",
+			ServingCertKeyPairSecret: configv1.SecretNameReference{
+				Name: origSecretName,
 			},
-		}
-		return err
+		},
+	}
+
+	componentRoutes, err = applyComponentRouteOverrides(ctx, c, scheme, relocation, componentRoutes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to apply ComponentRoute overrides: %w", err)
+	}
+
+	ingress := &configv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}
+	op, err = controllerutil.CreateOrPatch(ctx, c, ingress, func() error {
+		ingress.Spec.AppsDomain = fmt.Sprintf("apps.%s", relocation.Spec.Domain)
+		ingress.Spec.ComponentRoutes = componentRoutes
+		return nil
 	})
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	if op != controllerutil.OperationResultNone {
 		logger.Info("Ingress domain aliases modified", "OperationResult", op)
 	}
 
+	return requeueAfter, nil
+}
+
+// componentRouteCertSecretName is the deterministic name a ComponentRouteOverride's serving
+// certificate is copied to in openshift-config, so it can be located on subsequent reconciles.
+func componentRouteCertSecretName(overrideName string) string {
+	return fmt.Sprintf("componentroute-%s-cert", overrideName)
+}
+
+// applyComponentRouteOverrides copies each override's serving certificate into openshift-config
+// and merges it onto defaults: overrides matching a default ComponentRoute's Name replace it in
+// place, and overrides with no match (e.g. a user-defined alertmanager-main route) are appended.
+func applyComponentRouteOverrides(ctx context.Context, c client.Client, scheme *runtime.Scheme, relocation *rhsysenggithubiov1beta1.ClusterRelocation, defaults []configv1.ComponentRouteSpec) ([]configv1.ComponentRouteSpec, error) {
+	byName := make(map[string]int, len(defaults))
+	for i, route := range defaults {
+		byName[route.Name] = i
+	}
+
+	routes := defaults
+	for _, override := range relocation.Spec.ComponentRouteOverrides {
+		secretName := ""
+		if override.ServingCertRef != nil {
+			if err := secrets.ValidateCertSecret(ctx, c, override.ServingCertRef); err != nil {
+				return nil, err
+			}
+
+			secretName = componentRouteCertSecretName(override.Name)
+			copySettings := secrets.SecretCopySettings{
+				OwnOriginal:                  true,
+				OriginalOwnedByController:    false,
+				OwnDestination:               true,
+				DestinationOwnedByController: true,
+			}
+			if _, err := secrets.CopySecret(ctx, c, relocation, scheme, override.ServingCertRef.Name, override.ServingCertRef.Namespace, secretName, rhsysenggithubiov1beta1.ConfigNamespace, copySettings); err != nil {
+				return nil, err
+			}
+		}
+
+		route := configv1.ComponentRouteSpec{
+			Name:      override.Name,
+			Namespace: override.Namespace,
+			Hostname:  configv1.Hostname(override.Hostname),
+		}
+		if secretName != "" {
+			route.ServingCertKeyPairSecret = configv1.SecretNameReference{Name: secretName}
+		} else if i, ok := byName[override.Name]; ok {
+			// No override cert was given for an existing route: keep serving it from the
+			// default Ingress certificate rather than leaving it with an empty reference.
+			route.ServingCertKeyPairSecret = routes[i].ServingCertKeyPairSecret
+		}
+
+		if i, ok := byName[override.Name]; ok {
+			routes[i] = route
+		} else {
+			byName[override.Name] = len(routes)
+			routes = append(routes, route)
+		}
+	}
+	return routes, nil
+}
+
+// applyIngressPublishingStrategy sets ic's EndpointPublishingStrategy to match strategy,
+// snapshotting the pre-existing strategy into an annotation on first use so Cleanup can restore
+// it later. lbScope, if set, overrides the Scope that strategy would otherwise default to.
+func applyIngressPublishingStrategy(ic *operatorv1.IngressController, strategy rhsysenggithubiov1beta1.IngressPublishingStrategy, lbScope *operatorv1.LoadBalancerScope) error {
+	if _, snapshotted := ic.Annotations[originalPublishingStrategyAnnotation]; !snapshotted {
+		original, err := json.Marshal(ic.Spec.EndpointPublishingStrategy)
+		if err != nil {
+			return fmt.Errorf("failed to snapshot original EndpointPublishingStrategy: %w", err)
+		}
+		if ic.Annotations == nil {
+			ic.Annotations = map[string]string{}
+		}
+		ic.Annotations[originalPublishingStrategyAnnotation] = string(original)
+	}
+
+	switch strategy {
+	case rhsysenggithubiov1beta1.IngressPublishingStrategyExternal:
+		ic.Spec.EndpointPublishingStrategy = &operatorv1.EndpointPublishingStrategy{
+			Type:         operatorv1.LoadBalancerServiceStrategyType,
+			LoadBalancer: &operatorv1.LoadBalancerStrategy{Scope: operatorv1.ExternalLoadBalancer},
+		}
+	case rhsysenggithubiov1beta1.IngressPublishingStrategyInternal:
+		ic.Spec.EndpointPublishingStrategy = &operatorv1.EndpointPublishingStrategy{
+			Type:         operatorv1.LoadBalancerServiceStrategyType,
+			LoadBalancer: &operatorv1.LoadBalancerStrategy{Scope: operatorv1.InternalLoadBalancer},
+		}
+	case rhsysenggithubiov1beta1.IngressPublishingStrategyNodePortService:
+		ic.Spec.EndpointPublishingStrategy = &operatorv1.EndpointPublishingStrategy{
+			Type: operatorv1.NodePortServiceStrategyType,
+		}
+	case rhsysenggithubiov1beta1.IngressPublishingStrategyHostNetwork:
+		ic.Spec.EndpointPublishingStrategy = &operatorv1.EndpointPublishingStrategy{
+			Type: operatorv1.HostNetworkStrategyType,
+		}
+	default:
+		return fmt.Errorf("unsupported IngressPublishingStrategy %q", strategy)
+	}
+
+	if lbScope != nil && ic.Spec.EndpointPublishingStrategy.LoadBalancer != nil {
+		ic.Spec.EndpointPublishingStrategy.LoadBalancer.Scope = *lbScope
+	}
+
 	return nil
 }
 
@@ -197,6 +354,14 @@ func Cleanup(ctx context.Context, c client.Client, logger logr.Logger) error {
 	ingressController := &operatorv1.IngressController{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
 	op, err := controllerutil.CreateOrPatch(ctx, c, ingressController, func() error {
 		ingressController.Spec.DefaultCertificate = nil
+		if original, ok := ingressController.Annotations[originalPublishingStrategyAnnotation]; ok {
+			var strategy *operatorv1.EndpointPublishingStrategy
+			if err := json.Unmarshal([]byte(original), &strategy); err != nil {
+				return fmt.Errorf("failed to restore original EndpointPublishingStrategy: %w", err)
+			}
+			ingressController.Spec.EndpointPublishingStrategy = strategy
+			delete(ingressController.Annotations, originalPublishingStrategyAnnotation)
+		}
 		return nil
 	})
 	if err != nil {
@@ -208,6 +373,8 @@ func Cleanup(ctx context.Context, c client.Client, logger logr.Logger) error {
 	ingress := &configv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}
 	op, err = controllerutil.CreateOrPatch(ctx, c, ingress, func() error {
 		ingress.Spec.AppsDomain = ""
+		// This also reverts any ComponentRouteOverrides; their copied cert Secrets are owned by
+		// the ClusterRelocation and are garbage collected along with it.
 		ingress.Spec.ComponentRoutes = nil
 		return nil
 	})
@@ -220,34 +387,3 @@ func Cleanup(ctx context.Context, c client.Client, logger logr.Logger) error {
 
 	return nil
 }
-
-func ResetRoutes(ctx context.Context, c client.Client, domainName string, logger logr.Logger) error {
-	routes := &routev1.RouteList{}
-	if err := c.List(ctx, routes); err != nil {
-		return err
-	}
-
-	if err := util.WaitForCO(ctx, c, logger, "openshift-apiserver"); err != nil {
-		return err
-	}
-
-	for _, v := range routes.Items {
-		if v.Namespace == "openshift-console" || v.Namespace == "openshift-authentication" || v.Namespace == "open-cluster-management-agent-addon" {
-			// open-cluster-management-agent-addon is ignored because right now the Klusterlet Add-on ignores the "appsDomain" setting
-			// A PR has been opened to correct this: https://github.com/stolostron/multicloud-operators-foundation/pull/642
-			// without this fix, the Route created by the Klusterlet is always re-created with the original domain
-			continue
-		}
-		for _, w := range v.Status.Ingress {
-			if w.RouterName == "default" { // check Routes associated with the default Ingress Controller
-				if !strings.Contains(w.Host, domainName) { // hostname for this route needs to be updated
-					if err := c.Delete(ctx, &v); err != nil {
-						return err
-					}
-					logger.Info("Deleted Route so that it can be re-created with new domain", "Route", v.Name, "Host", w.Host, "namespace", v.Namespace)
-				}
-			}
-		}
-	}
-	return nil
-}
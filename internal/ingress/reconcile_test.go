@@ -0,0 +1,212 @@
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	rhsysenggithubiov1beta1 "github.com/RHsyseng/cluster-relocation-operator/api/v1beta1"
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newReconcileTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	if err := rhsysenggithubiov1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	return scheme
+}
+
+func TestApplyIngressPublishingStrategy(t *testing.T) {
+	cases := []struct {
+		strategy    rhsysenggithubiov1beta1.IngressPublishingStrategy
+		wantType    operatorv1.EndpointPublishingStrategyType
+		wantLBScope *operatorv1.LoadBalancerScope
+	}{
+		{rhsysenggithubiov1beta1.IngressPublishingStrategyExternal, operatorv1.LoadBalancerServiceStrategyType, &operatorv1.ExternalLoadBalancer},
+		{rhsysenggithubiov1beta1.IngressPublishingStrategyInternal, operatorv1.LoadBalancerServiceStrategyType, &operatorv1.InternalLoadBalancer},
+		{rhsysenggithubiov1beta1.IngressPublishingStrategyNodePortService, operatorv1.NodePortServiceStrategyType, nil},
+		{rhsysenggithubiov1beta1.IngressPublishingStrategyHostNetwork, operatorv1.HostNetworkStrategyType, nil},
+	}
+
+	for _, tc := range cases {
+		ic := &operatorv1.IngressController{}
+		if err := applyIngressPublishingStrategy(ic, tc.strategy, nil); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.strategy, err)
+		}
+		if ic.Spec.EndpointPublishingStrategy.Type != tc.wantType {
+			t.Errorf("%s: got type %s, want %s", tc.strategy, ic.Spec.EndpointPublishingStrategy.Type, tc.wantType)
+		}
+		if tc.wantLBScope != nil {
+			if ic.Spec.EndpointPublishingStrategy.LoadBalancer == nil || ic.Spec.EndpointPublishingStrategy.LoadBalancer.Scope != *tc.wantLBScope {
+				t.Errorf("%s: got LoadBalancer scope %+v, want %s", tc.strategy, ic.Spec.EndpointPublishingStrategy.LoadBalancer, *tc.wantLBScope)
+			}
+		}
+	}
+}
+
+func TestApplyIngressPublishingStrategyLoadBalancerScopeOverride(t *testing.T) {
+	ic := &operatorv1.IngressController{}
+	if err := applyIngressPublishingStrategy(ic, rhsysenggithubiov1beta1.IngressPublishingStrategyExternal, &operatorv1.InternalLoadBalancer); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ic.Spec.EndpointPublishingStrategy.LoadBalancer.Scope != operatorv1.InternalLoadBalancer {
+		t.Errorf("expected InternalLoadBalancerScope override to win, got %s", ic.Spec.EndpointPublishingStrategy.LoadBalancer.Scope)
+	}
+}
+
+func TestApplyIngressPublishingStrategySnapshotsOriginalOnce(t *testing.T) {
+	ic := &operatorv1.IngressController{
+		Spec: operatorv1.IngressControllerSpec{
+			EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{Type: operatorv1.HostNetworkStrategyType},
+		},
+	}
+
+	if err := applyIngressPublishingStrategy(ic, rhsysenggithubiov1beta1.IngressPublishingStrategyExternal, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	snapshot, ok := ic.Annotations[originalPublishingStrategyAnnotation]
+	if !ok {
+		t.Fatalf("expected original EndpointPublishingStrategy to be snapshotted into an annotation")
+	}
+
+	// A second reconcile with a different strategy must not overwrite the original snapshot.
+	if err := applyIngressPublishingStrategy(ic, rhsysenggithubiov1beta1.IngressPublishingStrategyNodePortService, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ic.Annotations[originalPublishingStrategyAnnotation] != snapshot {
+		t.Errorf("expected original snapshot to be preserved across repeated reconciles")
+	}
+}
+
+func TestApplyIngressPublishingStrategyUnsupported(t *testing.T) {
+	ic := &operatorv1.IngressController{}
+	if err := applyIngressPublishingStrategy(ic, "bogus", nil); err == nil {
+		t.Errorf("expected an error for an unsupported strategy")
+	}
+}
+
+func defaultTestComponentRoutes() []configv1.ComponentRouteSpec {
+	return []configv1.ComponentRouteSpec{
+		{
+			Name:                     "console",
+			Namespace:                "openshift-console",
+			Hostname:                 "console-openshift-console.apps.example.com",
+			ServingCertKeyPairSecret: configv1.SecretNameReference{Name: "copied-ingress-secret"},
+		},
+		{
+			Name:                     "downloads",
+			Namespace:                "openshift-console",
+			Hostname:                 "downloads-openshift-console.apps.example.com",
+			ServingCertKeyPairSecret: configv1.SecretNameReference{Name: "copied-ingress-secret"},
+		},
+	}
+}
+
+func TestApplyComponentRouteOverridesReplacesDefaultWithoutServingCertRef(t *testing.T) {
+	scheme := newReconcileTestScheme(t)
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	relocation := &rhsysenggithubiov1beta1.ClusterRelocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: rhsysenggithubiov1beta1.ClusterRelocationSpec{
+			Domain: "example.com",
+			ComponentRouteOverrides: []rhsysenggithubiov1beta1.ComponentRouteOverride{
+				{Name: "console", Namespace: "openshift-console", Hostname: "console.relocated.example.com"},
+			},
+		},
+	}
+
+	routes, err := applyComponentRouteOverrides(context.Background(), c, scheme, relocation, defaultTestComponentRoutes())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(routes) != 2 {
+		t.Fatalf("expected overriding an existing route to not change the route count, got %d", len(routes))
+	}
+	if routes[0].Hostname != "console.relocated.example.com" {
+		t.Errorf("expected console route's hostname to be overridden, got %q", routes[0].Hostname)
+	}
+	if routes[0].ServingCertKeyPairSecret.Name != "copied-ingress-secret" {
+		t.Errorf("expected console route to keep using the default Ingress certificate when no ServingCertRef is given, got %q", routes[0].ServingCertKeyPairSecret.Name)
+	}
+}
+
+func TestApplyComponentRouteOverridesReplacesDefaultWithServingCertRef(t *testing.T) {
+	scheme := newReconcileTestScheme(t)
+	certSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "console-cert", Namespace: "my-app"},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte("cert"),
+			corev1.TLSPrivateKeyKey: []byte("key"),
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(certSecret).Build()
+	relocation := &rhsysenggithubiov1beta1.ClusterRelocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: rhsysenggithubiov1beta1.ClusterRelocationSpec{
+			Domain: "example.com",
+			ComponentRouteOverrides: []rhsysenggithubiov1beta1.ComponentRouteOverride{
+				{
+					Name:           "console",
+					Namespace:      "openshift-console",
+					Hostname:       "console.relocated.example.com",
+					ServingCertRef: &rhsysenggithubiov1beta1.SecretReference{Name: "console-cert", Namespace: "my-app"},
+				},
+			},
+		},
+	}
+
+	routes, err := applyComponentRouteOverrides(context.Background(), c, scheme, relocation, defaultTestComponentRoutes())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(routes) != 2 {
+		t.Fatalf("expected overriding an existing route to not change the route count, got %d", len(routes))
+	}
+
+	wantSecretName := componentRouteCertSecretName("console")
+	if routes[0].ServingCertKeyPairSecret.Name != wantSecretName {
+		t.Errorf("got ServingCertKeyPairSecret %q, want %q", routes[0].ServingCertKeyPairSecret.Name, wantSecretName)
+	}
+
+	copied := &corev1.Secret{}
+	if err := c.Get(context.Background(), types.NamespacedName{Name: wantSecretName, Namespace: rhsysenggithubiov1beta1.ConfigNamespace}, copied); err != nil {
+		t.Fatalf("expected override's ServingCertRef to be copied into %s: %v", rhsysenggithubiov1beta1.ConfigNamespace, err)
+	}
+}
+
+func TestApplyComponentRouteOverridesAppendsNewRoute(t *testing.T) {
+	scheme := newReconcileTestScheme(t)
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	relocation := &rhsysenggithubiov1beta1.ClusterRelocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: rhsysenggithubiov1beta1.ClusterRelocationSpec{
+			Domain: "example.com",
+			ComponentRouteOverrides: []rhsysenggithubiov1beta1.ComponentRouteOverride{
+				{Name: "alertmanager-main", Namespace: "openshift-monitoring", Hostname: "alertmanager-main.apps.example.com"},
+			},
+		},
+	}
+
+	routes, err := applyComponentRouteOverrides(context.Background(), c, scheme, relocation, defaultTestComponentRoutes())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(routes) != 3 {
+		t.Fatalf("expected a user-defined ComponentRoute with no default match to be appended, got %d routes", len(routes))
+	}
+	if routes[2].Name != "alertmanager-main" || routes[2].Hostname != "alertmanager-main.apps.example.com" {
+		t.Errorf("unexpected appended route: %+v", routes[2])
+	}
+}
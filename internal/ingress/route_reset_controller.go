@@ -0,0 +1,228 @@
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	rhsysenggithubiov1beta1 "github.com/RHsyseng/cluster-relocation-operator/api/v1beta1"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// defaultRouteResetExclusions are the operator's built-in exclusions, merged with
+// Spec.RouteResetExclusions on every reconcile: Routes in openshift-console and
+// openshift-authentication are reconciled via the cluster Ingress's ComponentRoutes instead of
+// this controller, and open-cluster-management-agent-addon is skipped because the Klusterlet
+// Add-on currently ignores the cluster's appsDomain setting (see
+// https://github.com/stolostron/multicloud-operators-foundation/pull/642).
+var defaultRouteResetExclusions = []rhsysenggithubiov1beta1.NamespacedNameSelector{
+	{Namespace: "openshift-console"},
+	{Namespace: "openshift-authentication"},
+	{Namespace: "open-cluster-management-agent-addon"},
+}
+
+//+kubebuilder:rbac:groups=route.openshift.io,resources=routes,verbs=list;delete;watch
+
+// RouteResetReconciler watches Routes and deletes any whose hostname no longer matches the
+// ClusterRelocation's domain, so the default router re-creates them with the correct one. This
+// replaces a one-shot sweep with continuous healing, so Routes re-created post-relocation with
+// the stale domain (e.g. by Klusterlet) are corrected automatically until the CR is deleted.
+type RouteResetReconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+}
+
+func (r *RouteResetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	relocation, err := getClusterRelocation(ctx, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if relocation == nil || relocation.Spec.Domain == "" {
+		return ctrl.Result{}, nil
+	}
+
+	route := &routev1.Route{}
+	if err := r.Get(ctx, req.NamespacedName, route); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	excluded, err := routeIsExcluded(route, relocation.Spec.RouteResetExclusions)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if excluded {
+		return ctrl.Result{}, nil
+	}
+
+	staleHost, stale := staleIngressHost(route, relocation.Spec.Domain)
+	if !stale {
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.Delete(ctx, route); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	logger.Info("Deleted Route so that it can be re-created with new domain", "Route", route.Name, "Host", staleHost, "namespace", route.Namespace)
+	r.Recorder.Eventf(route, corev1.EventTypeNormal, "RouteHostnameStale", "Deleted Route so it can be re-created with the relocated domain (was %s)", staleHost)
+
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager registers the controller with mgr, filtering Route events to ones whose
+// default IngressController host has drifted from the current relocation domain, and also
+// watching ClusterRelocation so that a Domain change enqueues every existing Route for
+// re-evaluation (mirroring the original ResetRoutes sweep's trigger) rather than waiting for
+// something else to touch them first.
+func (r *RouteResetReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("route-reset-controller")
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&routev1.Route{}, builder.WithPredicates(r.staleHostnamePredicate())).
+		Watches(
+			&rhsysenggithubiov1beta1.ClusterRelocation{},
+			handler.EnqueueRequestsFromMapFunc(r.allRoutesOnDomainChange),
+			builder.WithPredicates(domainChangedPredicate{}),
+		).
+		Complete(r)
+}
+
+// allRoutesOnDomainChange enqueues every Route in the cluster, so that a ClusterRelocation Domain
+// change immediately re-evaluates Routes that are stale purely because of the change, rather than
+// relying on an unrelated event to touch them first.
+func (r *RouteResetReconciler) allRoutesOnDomainChange(ctx context.Context, _ client.Object) []reconcile.Request {
+	routes := &routev1.RouteList{}
+	if err := r.List(ctx, routes); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list Routes for ClusterRelocation domain change")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, len(routes.Items))
+	for i, route := range routes.Items {
+		requests[i] = reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&route)}
+	}
+	return requests
+}
+
+// domainChangedPredicate only passes through ClusterRelocation events where Spec.Domain changed,
+// so routine status-only updates don't trigger a full Route re-sweep.
+type domainChangedPredicate struct {
+	predicate.Funcs
+}
+
+func (domainChangedPredicate) Create(event.CreateEvent) bool {
+	return true
+}
+
+func (domainChangedPredicate) Update(e event.UpdateEvent) bool {
+	oldRelocation, ok := e.ObjectOld.(*rhsysenggithubiov1beta1.ClusterRelocation)
+	if !ok {
+		return false
+	}
+	newRelocation, ok := e.ObjectNew.(*rhsysenggithubiov1beta1.ClusterRelocation)
+	if !ok {
+		return false
+	}
+	return oldRelocation.Spec.Domain != newRelocation.Spec.Domain
+}
+
+func (domainChangedPredicate) Delete(event.DeleteEvent) bool {
+	return false
+}
+
+func (r *RouteResetReconciler) staleHostnamePredicate() predicate.Funcs {
+	return predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		route, ok := obj.(*routev1.Route)
+		if !ok {
+			return false
+		}
+
+		relocation, err := getClusterRelocation(context.Background(), r.Client)
+		if err != nil || relocation == nil || relocation.Spec.Domain == "" {
+			return false
+		}
+
+		if excluded, err := routeIsExcluded(route, relocation.Spec.RouteResetExclusions); err != nil || excluded {
+			return false
+		}
+
+		_, stale := staleIngressHost(route, relocation.Spec.Domain)
+		return stale
+	})
+}
+
+// staleIngressHost returns the default IngressController's current host for route and whether
+// it has drifted from domainName.
+func staleIngressHost(route *routev1.Route, domainName string) (string, bool) {
+	for _, ing := range route.Status.Ingress {
+		if ing.RouterName == "default" && !strings.Contains(ing.Host, domainName) {
+			return ing.Host, true
+		}
+	}
+	return "", false
+}
+
+// routeIsExcluded reports whether route matches any of the operator's built-in exclusions or
+// any of the user-supplied ones.
+func routeIsExcluded(route *routev1.Route, userExclusions []rhsysenggithubiov1beta1.NamespacedNameSelector) (bool, error) {
+	for _, selector := range append(defaultRouteResetExclusions, userExclusions...) {
+		matches, err := namespacedNameSelectorMatches(selector, route)
+		if err != nil {
+			return false, err
+		}
+		if matches {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func namespacedNameSelectorMatches(selector rhsysenggithubiov1beta1.NamespacedNameSelector, route *routev1.Route) (bool, error) {
+	if selector.Namespace != "" && selector.Namespace != route.Namespace {
+		return false, nil
+	}
+	if selector.LabelSelector != nil {
+		labelSelector, err := metav1.LabelSelectorAsSelector(selector.LabelSelector)
+		if err != nil {
+			return false, fmt.Errorf("invalid RouteResetExclusions label selector: %w", err)
+		}
+		if !labelSelector.Matches(labels.Set(route.Labels)) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// getClusterRelocation returns the cluster's singleton ClusterRelocation, or nil if none exists
+// yet.
+func getClusterRelocation(ctx context.Context, c client.Client) (*rhsysenggithubiov1beta1.ClusterRelocation, error) {
+	list := &rhsysenggithubiov1beta1.ClusterRelocationList{}
+	if err := c.List(ctx, list); err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+	return &list.Items[0], nil
+}
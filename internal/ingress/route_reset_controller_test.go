@@ -0,0 +1,131 @@
+package ingress
+
+import (
+	"testing"
+
+	rhsysenggithubiov1beta1 "github.com/RHsyseng/cluster-relocation-operator/api/v1beta1"
+	routev1 "github.com/openshift/api/route/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func newTestRoute(namespace string, labels map[string]string, host string) *routev1.Route {
+	return &routev1.Route{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-route", Namespace: namespace, Labels: labels},
+		Status: routev1.RouteStatus{
+			Ingress: []routev1.RouteIngress{
+				{RouterName: "default", Host: host},
+			},
+		},
+	}
+}
+
+func TestStaleIngressHost(t *testing.T) {
+	route := newTestRoute("my-app", nil, "my-app.apps.old.example.com")
+
+	if _, stale := staleIngressHost(route, "apps.old.example.com"); stale {
+		t.Errorf("expected host matching domain to not be stale")
+	}
+	if host, stale := staleIngressHost(route, "apps.new.example.com"); !stale || host != "my-app.apps.old.example.com" {
+		t.Errorf("expected host mismatching domain to be stale, got host=%q stale=%v", host, stale)
+	}
+}
+
+func TestStaleIngressHostIgnoresNonDefaultRouter(t *testing.T) {
+	route := &routev1.Route{
+		Status: routev1.RouteStatus{
+			Ingress: []routev1.RouteIngress{
+				{RouterName: "sharded", Host: "my-app.apps.old.example.com"},
+			},
+		},
+	}
+
+	if _, stale := staleIngressHost(route, "apps.new.example.com"); stale {
+		t.Errorf("expected only the default router's ingress to be considered")
+	}
+}
+
+func TestRouteIsExcludedDefaultNamespace(t *testing.T) {
+	route := newTestRoute("openshift-console", nil, "console.apps.old.example.com")
+
+	excluded, err := routeIsExcluded(route, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !excluded {
+		t.Errorf("expected route in openshift-console to be excluded by default")
+	}
+}
+
+func TestRouteIsExcludedUserNamespace(t *testing.T) {
+	route := newTestRoute("my-app", nil, "my-app.apps.old.example.com")
+	userExclusions := []rhsysenggithubiov1beta1.NamespacedNameSelector{{Namespace: "my-app"}}
+
+	excluded, err := routeIsExcluded(route, userExclusions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !excluded {
+		t.Errorf("expected route to be excluded by user-supplied namespace selector")
+	}
+}
+
+func TestRouteIsExcludedLabelSelector(t *testing.T) {
+	route := newTestRoute("my-app", map[string]string{"keep": "true"}, "my-app.apps.old.example.com")
+	userExclusions := []rhsysenggithubiov1beta1.NamespacedNameSelector{
+		{LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"keep": "true"}}},
+	}
+
+	excluded, err := routeIsExcluded(route, userExclusions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !excluded {
+		t.Errorf("expected route matching label selector to be excluded")
+	}
+
+	other := newTestRoute("my-app", map[string]string{"keep": "false"}, "my-app.apps.old.example.com")
+	excluded, err = routeIsExcluded(other, userExclusions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if excluded {
+		t.Errorf("expected route not matching label selector to not be excluded")
+	}
+}
+
+func TestDomainChangedPredicateUpdate(t *testing.T) {
+	predicate := domainChangedPredicate{}
+
+	oldRelocation := &rhsysenggithubiov1beta1.ClusterRelocation{
+		Spec: rhsysenggithubiov1beta1.ClusterRelocationSpec{Domain: "old.example.com"},
+	}
+	sameDomain := &rhsysenggithubiov1beta1.ClusterRelocation{
+		Spec: rhsysenggithubiov1beta1.ClusterRelocationSpec{Domain: "old.example.com"},
+	}
+	newDomain := &rhsysenggithubiov1beta1.ClusterRelocation{
+		Spec: rhsysenggithubiov1beta1.ClusterRelocationSpec{Domain: "new.example.com"},
+	}
+
+	if predicate.Update(event.UpdateEvent{ObjectOld: oldRelocation, ObjectNew: sameDomain}) {
+		t.Errorf("expected no event when Domain is unchanged")
+	}
+	if !predicate.Update(event.UpdateEvent{ObjectOld: oldRelocation, ObjectNew: newDomain}) {
+		t.Errorf("expected an event when Domain changed")
+	}
+}
+
+func TestRouteIsExcludedNamespaceAndLabelSelectorBothMustMatch(t *testing.T) {
+	route := newTestRoute("other-ns", map[string]string{"keep": "true"}, "my-app.apps.old.example.com")
+	userExclusions := []rhsysenggithubiov1beta1.NamespacedNameSelector{
+		{Namespace: "my-app", LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"keep": "true"}}},
+	}
+
+	excluded, err := routeIsExcluded(route, userExclusions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if excluded {
+		t.Errorf("expected selector requiring both namespace and labels to not match a route in a different namespace")
+	}
+}
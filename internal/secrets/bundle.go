@@ -0,0 +1,199 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	rhsysenggithubiov1beta1 "github.com/RHsyseng/cluster-relocation-operator/api/v1beta1"
+	keystore "github.com/pavlo-v-chernykh/keystore-go/v4"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// Secret data keys that identify a PKCS#12 or JKS bundle, following the convention used by
+// cert-manager's `keystores:` field.
+const (
+	pkcs12DataKey = "keystore.p12"
+	pkcs12PassKey = "keystore-password"
+	jksDataKey    = "keystore.jks"
+	jksPassKey    = "keystore-password"
+
+	// caCertKey is the data key used for the intermediate chain extracted from a bundle,
+	// mirroring the ca.crt convention used elsewhere in OpenShift trust bundles.
+	caCertKey = "ca.crt"
+)
+
+// BundleFormat identifies the encoding of a cert/key bundle supplied via a Secret.
+type BundleFormat string
+
+const (
+	// BundleFormatPEM is a standard kubernetes.io/tls Secret containing PEM tls.crt/tls.key.
+	BundleFormatPEM BundleFormat = "PEM"
+	// BundleFormatPKCS12 is a password-protected PKCS#12/PFX bundle, e.g. cert-manager's
+	// keystores.pkcs12 output.
+	BundleFormatPKCS12 BundleFormat = "PKCS12"
+	// BundleFormatJKS is a password-protected Java KeyStore, e.g. cert-manager's
+	// keystores.jks output.
+	BundleFormatJKS BundleFormat = "JKS"
+)
+
+// ValidateCertSecret fetches the referenced Secret and ensures it's a recognized certificate
+// source: a kubernetes.io/tls Secret holding PEM tls.crt/tls.key, or an Opaque Secret holding a
+// PKCS#12 or JKS bundle (e.g. from cert-manager's keystores: field) with its password key set.
+func ValidateCertSecret(ctx context.Context, c client.Client, ref *rhsysenggithubiov1beta1.SecretReference) error {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: ref.Namespace}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("secret %s/%s not found", ref.Namespace, ref.Name)
+		}
+		return err
+	}
+
+	switch DetectBundleFormat(secret) {
+	case BundleFormatPKCS12:
+		if len(secret.Data[pkcs12PassKey]) == 0 {
+			return fmt.Errorf("secret %s/%s is missing %q required to decrypt the PKCS#12 bundle", ref.Namespace, ref.Name, pkcs12PassKey)
+		}
+	case BundleFormatJKS:
+		if len(secret.Data[jksPassKey]) == 0 {
+			return fmt.Errorf("secret %s/%s is missing %q required to decrypt the JKS keystore", ref.Namespace, ref.Name, jksPassKey)
+		}
+	default:
+		if secret.Type != corev1.SecretTypeTLS {
+			return fmt.Errorf("secret %s/%s must be of type %s, got %s", ref.Namespace, ref.Name, corev1.SecretTypeTLS, secret.Type)
+		}
+	}
+	return nil
+}
+
+// DetectBundleFormat infers the bundle format of a secret from the data keys it carries.
+func DetectBundleFormat(secret *corev1.Secret) BundleFormat {
+	if _, ok := secret.Data[pkcs12DataKey]; ok {
+		return BundleFormatPKCS12
+	}
+	if _, ok := secret.Data[jksDataKey]; ok {
+		return BundleFormatJKS
+	}
+	return BundleFormatPEM
+}
+
+// NormalizeTLSData returns kubernetes.io/tls-shaped data (tls.crt, tls.key, and ca.crt when
+// intermediates are present) for secret, decoding PKCS#12 or JKS bundles as needed so that
+// downstream consumers never have to special-case the original encoding. PEM secrets are
+// returned unchanged.
+func NormalizeTLSData(secret *corev1.Secret) (map[string][]byte, error) {
+	switch DetectBundleFormat(secret) {
+	case BundleFormatPKCS12:
+		return normalizePKCS12(secret.Data[pkcs12DataKey], secret.Data[pkcs12PassKey])
+	case BundleFormatJKS:
+		return normalizeJKS(secret.Data[jksDataKey], secret.Data[jksPassKey])
+	default:
+		return secret.Data, nil
+	}
+}
+
+func normalizePKCS12(data, password []byte) (map[string][]byte, error) {
+	privateKey, leaf, caCerts, err := pkcs12.DecodeChain(data, string(password))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode PKCS#12 bundle: %w", err)
+	}
+
+	keyPEM, err := marshalPrivateKeyPEM(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string][]byte{
+		corev1.TLSCertKey:       encodeCertPEM(leaf),
+		corev1.TLSPrivateKeyKey: keyPEM,
+	}
+	if len(caCerts) > 0 {
+		out[caCertKey] = encodeCertChainPEM(caCerts)
+	}
+	return out, nil
+}
+
+func normalizeJKS(data, password []byte) (map[string][]byte, error) {
+	ks := keystore.New()
+	if err := ks.Load(bytes.NewReader(data), password); err != nil {
+		return nil, fmt.Errorf("failed to decode JKS keystore: %w", err)
+	}
+
+	var entry keystore.PrivateKeyEntry
+	found := false
+	for _, alias := range ks.Aliases() {
+		if !ks.IsPrivateKeyEntry(alias) {
+			continue
+		}
+		e, err := ks.GetPrivateKeyEntry(alias, password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JKS private key entry %q: %w", alias, err)
+		}
+		entry = e
+		found = true
+		break
+	}
+	if !found {
+		return nil, fmt.Errorf("JKS keystore does not contain a private key entry")
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(entry.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JKS private key: %w", err)
+	}
+	keyPEM, err := marshalPrivateKeyPEM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entry.CertificateChain) == 0 {
+		return nil, fmt.Errorf("JKS private key entry has no associated certificate chain")
+	}
+	leaf, err := x509.ParseCertificate(entry.CertificateChain[0].Content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JKS leaf certificate: %w", err)
+	}
+
+	out := map[string][]byte{
+		corev1.TLSCertKey:       encodeCertPEM(leaf),
+		corev1.TLSPrivateKeyKey: keyPEM,
+	}
+	if len(entry.CertificateChain) > 1 {
+		var intermediates []*x509.Certificate
+		for _, c := range entry.CertificateChain[1:] {
+			cert, err := x509.ParseCertificate(c.Content)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse JKS intermediate certificate: %w", err)
+			}
+			intermediates = append(intermediates, cert)
+		}
+		out[caCertKey] = encodeCertChainPEM(intermediates)
+	}
+	return out, nil
+}
+
+func marshalPrivateKeyPEM(key any) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+func encodeCertPEM(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
+
+func encodeCertChainPEM(certs []*x509.Certificate) []byte {
+	var buf bytes.Buffer
+	for _, cert := range certs {
+		buf.Write(encodeCertPEM(cert))
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,158 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	rhsysenggithubiov1beta1 "github.com/RHsyseng/cluster-relocation-operator/api/v1beta1"
+	keystore "github.com/pavlo-v-chernykh/keystore-go/v4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func generateTestCertAndKey(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "*.apps.example.com"},
+		DNSNames:     []string{"*.apps.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return cert, key
+}
+
+func TestNormalizeTLSDataPassesThroughPEM(t *testing.T) {
+	secret := &corev1.Secret{
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte("cert"),
+			corev1.TLSPrivateKeyKey: []byte("key"),
+		},
+	}
+	data, err := NormalizeTLSData(secret)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(data[corev1.TLSCertKey]) != "cert" {
+		t.Fatalf("expected PEM data to pass through unchanged, got %q", data[corev1.TLSCertKey])
+	}
+}
+
+func TestNormalizeTLSDataDecodesPKCS12(t *testing.T) {
+	cert, key := generateTestCertAndKey(t)
+	pfxData, err := pkcs12.Modern.Encode(key, cert, nil, "s3cr3t")
+	if err != nil {
+		t.Fatalf("failed to build PKCS#12 bundle: %v", err)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "keystore-secret", Namespace: "default"},
+		Data: map[string][]byte{
+			pkcs12DataKey: pfxData,
+			pkcs12PassKey: []byte("s3cr3t"),
+		},
+	}
+
+	if format := DetectBundleFormat(secret); format != BundleFormatPKCS12 {
+		t.Fatalf("expected format %s, got %s", BundleFormatPKCS12, format)
+	}
+
+	data, err := NormalizeTLSData(secret)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(data[corev1.TLSCertKey]) == 0 || len(data[corev1.TLSPrivateKeyKey]) == 0 {
+		t.Fatal("expected normalized tls.crt/tls.key to be populated")
+	}
+
+	leaf, err := parseCertPEM(data[corev1.TLSCertKey])
+	if err != nil {
+		t.Fatalf("failed to parse normalized leaf cert: %v", err)
+	}
+	if leaf.Subject.CommonName != "*.apps.example.com" {
+		t.Fatalf("unexpected common name: %s", leaf.Subject.CommonName)
+	}
+}
+
+func TestNormalizeTLSDataDecodesJKS(t *testing.T) {
+	cert, key := generateTestCertAndKey(t)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal private key: %v", err)
+	}
+
+	ks := keystore.New()
+	entry := keystore.PrivateKeyEntry{
+		CreationTime:     time.Now(),
+		PrivateKey:       keyDER,
+		CertificateChain: []keystore.Certificate{{Type: "X509", Content: cert.Raw}},
+	}
+	if err := ks.SetPrivateKeyEntry("relocation", entry, []byte("s3cr3t")); err != nil {
+		t.Fatalf("failed to build JKS keystore: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := ks.Store(&buf, []byte("s3cr3t")); err != nil {
+		t.Fatalf("failed to serialize JKS keystore: %v", err)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "keystore-secret", Namespace: "default"},
+		Data: map[string][]byte{
+			jksDataKey: buf.Bytes(),
+			jksPassKey: []byte("s3cr3t"),
+		},
+	}
+
+	if format := DetectBundleFormat(secret); format != BundleFormatJKS {
+		t.Fatalf("expected format %s, got %s", BundleFormatJKS, format)
+	}
+
+	data, err := NormalizeTLSData(secret)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	leaf, err := parseCertPEM(data[corev1.TLSCertKey])
+	if err != nil {
+		t.Fatalf("failed to parse normalized leaf cert: %v", err)
+	}
+	if leaf.Subject.CommonName != "*.apps.example.com" {
+		t.Fatalf("unexpected common name: %s", leaf.Subject.CommonName)
+	}
+}
+
+func TestValidateCertSecretRejectsPKCS12WithoutPassword(t *testing.T) {
+	scheme := newTestScheme(t)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "keystore-secret", Namespace: "default"},
+		Data:       map[string][]byte{pkcs12DataKey: []byte("not-a-real-bundle")},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+	ref := &rhsysenggithubiov1beta1.SecretReference{Name: "keystore-secret", Namespace: "default"}
+	if err := ValidateCertSecret(context.Background(), c, ref); err == nil {
+		t.Fatal("expected an error when the PKCS#12 password key is missing")
+	}
+}
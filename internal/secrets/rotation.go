@@ -0,0 +1,422 @@
+package secrets
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	rhsysenggithubiov1beta1 "github.com/RHsyseng/cluster-relocation-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Annotation keys mirroring library-go's certrotation package, so that the not-before/not-after
+// window of a managed certificate can be read back without re-parsing the PEM data.
+const (
+	CertificateNotBeforeAnnotation = "relocation.rhsyseng.github.io/certificate-not-before"
+	CertificateNotAfterAnnotation  = "relocation.rhsyseng.github.io/certificate-not-after"
+	CertificateIssuerAnnotation    = "relocation.rhsyseng.github.io/certificate-issuer"
+)
+
+// IngressCASecretName is the long-lived signing CA secret backing generated Ingress leaf certs.
+const IngressCASecretName = "ingress-signer-ca"
+
+// IngressCAConfigMapName is the well-known configmap, mirroring cluster-ingress-operator's
+// default-ingress-cert, that publishes the signing CA for in-cluster clients to trust.
+const IngressCAConfigMapName = "default-ingress-ca"
+
+// IngressCAConfigMapNamespace is the namespace cluster-ingress-operator publishes its
+// equivalent trust bundle configmap into.
+const IngressCAConfigMapNamespace = "openshift-config-managed"
+
+// previousCACertKey holds the CA certificate a rollover just replaced, retained alongside the
+// current one so a leaf issued under it keeps chaining (and the published trust bundle keeps
+// serving it) for a grace period, mirroring library-go's certrotation CA bundle retention. Only
+// the certificate is kept, never the private key, since it's only ever used to verify, not sign.
+const previousCACertKey = "ca-previous.crt"
+
+// caValidity is how long a freshly minted signing CA is valid for.
+const caValidity = 5 * 365 * 24 * time.Hour
+
+// leafValidity is how long a freshly minted leaf certificate is valid for.
+const leafValidity = 30 * 24 * time.Hour
+
+// SigningRotation manages a long-lived self-signed CA Secret, re-using it until it enters its
+// refresh window. Now is injectable so tests can simulate the passage of time.
+type SigningRotation struct {
+	Name      string
+	Namespace string
+	Validity  time.Duration
+	Refresh   time.Duration
+	Now       func() time.Time
+}
+
+// TargetRotation manages a short-lived leaf Secret signed by a SigningRotation's CA, re-issuing
+// it when it enters its refresh window, its SANs no longer match, or it chains to neither the
+// current CA nor the one that CA most recently replaced.
+type TargetRotation struct {
+	Name      string
+	Namespace string
+	Validity  time.Duration
+	Refresh   time.Duration
+	Hostnames []string
+	Now       func() time.Time
+}
+
+func (s SigningRotation) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+func (t TargetRotation) now() time.Time {
+	if t.Now != nil {
+		return t.Now()
+	}
+	return time.Now()
+}
+
+// EnsureSigningCertKeyPair reconciles the signing CA secret, generating a new CA when one
+// doesn't exist yet or the existing one has entered its refresh window (the last third of its
+// validity unless Refresh is set). When
+// it rotates the CA, the CA it's replacing is retained under previousCACertKey rather than
+// discarded, so a leaf already issued under it keeps verifying across the rollover instead of
+// being invalidated in the same reconcile pass that rotated its signer.
+// It returns the CA Secret, holding the certificate and key in PEM form.
+func (s SigningRotation) EnsureSigningCertKeyPair(ctx context.Context, c client.Client, owner *rhsysenggithubiov1beta1.ClusterRelocation, scheme *runtime.Scheme) (*corev1.Secret, controllerutil.OperationResult, error) {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: s.Name, Namespace: s.Namespace}}
+
+	op, err := controllerutil.CreateOrUpdate(ctx, c, secret, func() error {
+		if !s.needsNewCA(secret) {
+			return nil
+		}
+
+		previousCACert := secret.Data[corev1.TLSCertKey]
+
+		caCertPEM, caKeyPEM, notBefore, notAfter, err := generateCA(fmt.Sprintf("%s-signer", owner.Name), s.validity())
+		if err != nil {
+			return err
+		}
+
+		secret.Type = corev1.SecretTypeTLS
+		secret.Data = map[string][]byte{
+			corev1.TLSCertKey:       caCertPEM,
+			corev1.TLSPrivateKeyKey: caKeyPEM,
+		}
+		if len(previousCACert) > 0 {
+			secret.Data[previousCACertKey] = previousCACert
+		}
+		setValidityAnnotations(secret, notBefore, notAfter)
+		return controllerutil.SetControllerReference(owner, secret, scheme)
+	})
+	if err != nil {
+		return nil, controllerutil.OperationResultNone, err
+	}
+	return secret, op, nil
+}
+
+func (s SigningRotation) validity() time.Duration {
+	if s.Validity > 0 {
+		return s.Validity
+	}
+	return caValidity
+}
+
+// needsNewCA reports whether the CA secret is missing data or has entered its refresh window.
+func (s SigningRotation) needsNewCA(secret *corev1.Secret) bool {
+	if len(secret.Data[corev1.TLSCertKey]) == 0 {
+		return true
+	}
+	notAfter, err := certNotAfter(secret)
+	if err != nil {
+		return true
+	}
+	return s.now().After(refreshTime(certNotBefore(secret, s.now()), notAfter, s.Refresh))
+}
+
+// EnsureTargetCertKeyPair reconciles the leaf secret signed by the given CA, re-issuing it when
+// it's missing, in its refresh window, the requested hostnames changed, or it verifies against
+// neither the current CA nor the previous CA retained after a rollover.
+func (t TargetRotation) EnsureTargetCertKeyPair(ctx context.Context, c client.Client, owner *rhsysenggithubiov1beta1.ClusterRelocation, scheme *runtime.Scheme, ca *corev1.Secret) (*corev1.Secret, controllerutil.OperationResult, error) {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: t.Name, Namespace: t.Namespace}}
+
+	op, err := controllerutil.CreateOrUpdate(ctx, c, secret, func() error {
+		if !t.needsNewLeaf(secret, ca) {
+			return nil
+		}
+
+		leafCertPEM, leafKeyPEM, notBefore, notAfter, err := signLeaf(ca, t.Hostnames, t.validity())
+		if err != nil {
+			return err
+		}
+
+		secret.Type = corev1.SecretTypeTLS
+		secret.Data = map[string][]byte{
+			corev1.TLSCertKey:       leafCertPEM,
+			corev1.TLSPrivateKeyKey: leafKeyPEM,
+		}
+		setValidityAnnotations(secret, notBefore, notAfter)
+		return controllerutil.SetControllerReference(owner, secret, scheme)
+	})
+	if err != nil {
+		return nil, controllerutil.OperationResultNone, err
+	}
+	return secret, op, nil
+}
+
+func (t TargetRotation) validity() time.Duration {
+	if t.Validity > 0 {
+		return t.Validity
+	}
+	return leafValidity
+}
+
+// needsNewLeaf reports whether the leaf secret must be re-issued: it's missing, has entered its
+// refresh window, its SANs no longer match the requested hostnames, or it no longer chains to ca.
+func (t TargetRotation) needsNewLeaf(secret *corev1.Secret, ca *corev1.Secret) bool {
+	certPEM := secret.Data[corev1.TLSCertKey]
+	if len(certPEM) == 0 {
+		return true
+	}
+
+	cert, err := parseCertPEM(certPEM)
+	if err != nil {
+		return true
+	}
+
+	if !sameHostnames(cert.DNSNames, t.Hostnames) {
+		return true
+	}
+
+	if !chainsTo(cert, ca) {
+		return true
+	}
+
+	notAfter, err := certNotAfter(secret)
+	if err != nil {
+		return true
+	}
+	return t.now().After(refreshTime(certNotBefore(secret, t.now()), notAfter, t.Refresh))
+}
+
+// refreshTime returns the point at which a certificate with the given validity window should be
+// rotated: when it enters the last third of its lifetime, matching library-go's certrotation
+// default, unless an explicit refresh duration before expiry was requested.
+func refreshTime(notBefore, notAfter time.Time, refresh time.Duration) time.Time {
+	if refresh > 0 {
+		return notAfter.Add(-refresh)
+	}
+	validity := notAfter.Sub(notBefore)
+	return notAfter.Add(-validity / 3)
+}
+
+func setValidityAnnotations(secret *corev1.Secret, notBefore, notAfter time.Time) {
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[CertificateNotBeforeAnnotation] = notBefore.Format(time.RFC3339)
+	secret.Annotations[CertificateNotAfterAnnotation] = notAfter.Format(time.RFC3339)
+}
+
+func certNotBefore(secret *corev1.Secret, fallback time.Time) time.Time {
+	v, ok := secret.Annotations[CertificateNotBeforeAnnotation]
+	if !ok {
+		return fallback
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return fallback
+	}
+	return t
+}
+
+func certNotAfter(secret *corev1.Secret) (time.Time, error) {
+	v, ok := secret.Annotations[CertificateNotAfterAnnotation]
+	if !ok {
+		return time.Time{}, fmt.Errorf("secret %s/%s missing %s annotation", secret.Namespace, secret.Name, CertificateNotAfterAnnotation)
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+func sameHostnames(have, want []string) bool {
+	if len(have) != len(want) {
+		return false
+	}
+	for i := range want {
+		if have[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// chainsTo reports whether cert was signed by the current CA in ca, or by the CA it most
+// recently replaced, so a leaf issued just before a CA rollover keeps verifying for a grace
+// period rather than being rejected the moment the signer changes.
+func chainsTo(cert *x509.Certificate, ca *corev1.Secret) bool {
+	return chainsToCertPEM(cert, ca.Data[corev1.TLSCertKey]) || chainsToCertPEM(cert, ca.Data[previousCACertKey])
+}
+
+func chainsToCertPEM(cert *x509.Certificate, caCertPEM []byte) bool {
+	if len(caCertPEM) == 0 {
+		return false
+	}
+	caCert, err := parseCertPEM(caCertPEM)
+	if err != nil {
+		return false
+	}
+	return cert.CheckSignatureFrom(caCert) == nil
+}
+
+func parseCertPEM(certPEM []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block containing certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func generateCA(commonName string, validity time.Duration) (certPEM, keyPEM []byte, notBefore, notAfter time.Time, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, time.Time{}, time.Time{}, fmt.Errorf("failed to generate private key: %w", err)
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, time.Time{}, time.Time{}, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	notBefore = time.Now()
+	notAfter = notBefore.Add(validity)
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, time.Time{}, time.Time{}, fmt.Errorf("failed to create CA certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, time.Time{}, time.Time{}, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, notBefore, notAfter, nil
+}
+
+func signLeaf(ca *corev1.Secret, hostnames []string, validity time.Duration) (certPEM, keyPEM []byte, notBefore, notAfter time.Time, err error) {
+	if len(hostnames) == 0 {
+		return nil, nil, time.Time{}, time.Time{}, fmt.Errorf("at least one hostname is required")
+	}
+
+	caCert, err := parseCertPEM(ca.Data[corev1.TLSCertKey])
+	if err != nil {
+		return nil, nil, time.Time{}, time.Time{}, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+	caKeyBlock, _ := pem.Decode(ca.Data[corev1.TLSPrivateKeyKey])
+	if caKeyBlock == nil {
+		return nil, nil, time.Time{}, time.Time{}, fmt.Errorf("failed to decode PEM block containing CA private key")
+	}
+	caKey, err := x509.ParseECPrivateKey(caKeyBlock.Bytes)
+	if err != nil {
+		return nil, nil, time.Time{}, time.Time{}, fmt.Errorf("failed to parse CA private key: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, time.Time{}, time.Time{}, fmt.Errorf("failed to generate private key: %w", err)
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, time.Time{}, time.Time{}, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	notBefore = time.Now()
+	notAfter = notBefore.Add(validity)
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{CommonName: hostnames[0]},
+		DNSNames:              hostnames,
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, time.Time{}, time.Time{}, fmt.Errorf("failed to create leaf certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, time.Time{}, time.Time{}, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, notBefore, notAfter, nil
+}
+
+// EarliestRefresh returns the shortest duration from now until either secret's refresh time, so
+// callers can requeue reconciliation ahead of the next rotation rather than waiting for the
+// informer's full resync period. A past-due refresh time (e.g. one just acted on) yields 0.
+func EarliestRefresh(secrets ...*corev1.Secret) time.Duration {
+	now := time.Now()
+	var earliest time.Duration
+	found := false
+	for _, secret := range secrets {
+		notAfter, err := certNotAfter(secret)
+		if err != nil {
+			continue
+		}
+		refresh := refreshTime(certNotBefore(secret, now), notAfter, 0)
+		until := refresh.Sub(now)
+		if until < 0 {
+			until = 0
+		}
+		if !found || until < earliest {
+			earliest = until
+			found = true
+		}
+	}
+	return earliest
+}
+
+// EnsureIngressCAConfigMap publishes the CA certificate (plus the previous CA it replaced, if
+// any, so clients still trusting it aren't dropped mid-rollover) into the well-known configmap
+// that in-cluster golang clients trust for default routes, mirroring cluster-ingress-operator. It
+// is owned by owner so it's garbage collected along with the ClusterRelocation rather than
+// outliving it (or a later switch away from the self-signed rotation path).
+func EnsureIngressCAConfigMap(ctx context.Context, c client.Client, owner *rhsysenggithubiov1beta1.ClusterRelocation, scheme *runtime.Scheme, ca *corev1.Secret) (controllerutil.OperationResult, error) {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: IngressCAConfigMapName, Namespace: IngressCAConfigMapNamespace}}
+	return controllerutil.CreateOrUpdate(ctx, c, cm, func() error {
+		bundle := ca.Data[corev1.TLSCertKey]
+		if previous := ca.Data[previousCACertKey]; len(previous) > 0 {
+			bundle = append(append([]byte{}, bundle...), previous...)
+		}
+		cm.Data = map[string]string{"ca-bundle.crt": string(bundle)}
+		return controllerutil.SetControllerReference(owner, cm, scheme)
+	})
+}
@@ -0,0 +1,215 @@
+package secrets
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	rhsysenggithubiov1beta1 "github.com/RHsyseng/cluster-relocation-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	if err := rhsysenggithubiov1beta1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	return scheme
+}
+
+func newTestRelocation() *rhsysenggithubiov1beta1.ClusterRelocation {
+	return &rhsysenggithubiov1beta1.ClusterRelocation{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       rhsysenggithubiov1beta1.ClusterRelocationSpec{Domain: "example.com"},
+	}
+}
+
+func TestSigningRotationFreshGeneration(t *testing.T) {
+	scheme := newTestScheme(t)
+	relocation := newTestRelocation()
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	rotation := SigningRotation{Name: IngressCASecretName, Namespace: rhsysenggithubiov1beta1.IngressNamespace}
+	ca, _, err := rotation.EnsureSigningCertKeyPair(context.Background(), c, relocation, scheme)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ca.Data[corev1.TLSCertKey]) == 0 {
+		t.Fatal("expected CA cert data to be populated")
+	}
+	if ca.Annotations[CertificateNotAfterAnnotation] == "" {
+		t.Fatal("expected not-after annotation to be set")
+	}
+}
+
+func TestTargetRotationNoopWithinValidityWindow(t *testing.T) {
+	scheme := newTestScheme(t)
+	relocation := newTestRelocation()
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	ctx := context.Background()
+
+	signingRotation := SigningRotation{Name: IngressCASecretName, Namespace: rhsysenggithubiov1beta1.IngressNamespace}
+	ca, _, err := signingRotation.EnsureSigningCertKeyPair(ctx, c, relocation, scheme)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	targetRotation := TargetRotation{
+		Name:      "generated-ingress-secret",
+		Namespace: rhsysenggithubiov1beta1.IngressNamespace,
+		Hostnames: []string{"*.apps.example.com"},
+	}
+	first, _, err := targetRotation.EnsureTargetCertKeyPair(ctx, c, relocation, scheme, ca)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second, _, err := targetRotation.EnsureTargetCertKeyPair(ctx, c, relocation, scheme, ca)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(second.Data[corev1.TLSCertKey]) != string(first.Data[corev1.TLSCertKey]) {
+		t.Fatal("expected leaf cert to be unchanged when well within its validity window")
+	}
+}
+
+func TestTargetRotationForcedOnDomainChange(t *testing.T) {
+	scheme := newTestScheme(t)
+	relocation := newTestRelocation()
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	ctx := context.Background()
+
+	signingRotation := SigningRotation{Name: IngressCASecretName, Namespace: rhsysenggithubiov1beta1.IngressNamespace}
+	ca, _, err := signingRotation.EnsureSigningCertKeyPair(ctx, c, relocation, scheme)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	targetRotation := TargetRotation{
+		Name:      "generated-ingress-secret",
+		Namespace: rhsysenggithubiov1beta1.IngressNamespace,
+		Hostnames: []string{"*.apps.example.com"},
+	}
+	first, _, err := targetRotation.EnsureTargetCertKeyPair(ctx, c, relocation, scheme, ca)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	targetRotation.Hostnames = []string{"*.apps.relocated.example.com"}
+	second, _, err := targetRotation.EnsureTargetCertKeyPair(ctx, c, relocation, scheme, ca)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(second.Data[corev1.TLSCertKey]) == string(first.Data[corev1.TLSCertKey]) {
+		t.Fatal("expected leaf cert to be reissued when the requested hostname changed")
+	}
+
+	cert, err := parseCertPEM(second.Data[corev1.TLSCertKey])
+	if err != nil {
+		t.Fatalf("failed to parse reissued cert: %v", err)
+	}
+	if cert.DNSNames[0] != "*.apps.relocated.example.com" {
+		t.Fatalf("expected reissued cert to cover the new domain, got %v", cert.DNSNames)
+	}
+}
+
+func TestTargetRotationSurvivesCARolloverGracePeriod(t *testing.T) {
+	scheme := newTestScheme(t)
+	relocation := newTestRelocation()
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+	ctx := context.Background()
+
+	signingRotation := SigningRotation{Name: IngressCASecretName, Namespace: rhsysenggithubiov1beta1.IngressNamespace}
+	oldCA, _, err := signingRotation.EnsureSigningCertKeyPair(ctx, c, relocation, scheme)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	targetRotation := TargetRotation{
+		Name:      "generated-ingress-secret",
+		Namespace: rhsysenggithubiov1beta1.IngressNamespace,
+		Hostnames: []string{"*.apps.example.com"},
+	}
+	leaf, _, err := targetRotation.EnsureTargetCertKeyPair(ctx, c, relocation, scheme, oldCA)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	oldLeafCertPEM := string(leaf.Data[corev1.TLSCertKey])
+	leafCert, err := parseCertPEM(leaf.Data[corev1.TLSCertKey])
+	if err != nil {
+		t.Fatalf("failed to parse leaf cert: %v", err)
+	}
+	if !chainsTo(leafCert, oldCA) {
+		t.Fatal("expected freshly issued leaf to chain to the CA that signed it")
+	}
+
+	// Force a real CA rotation through EnsureSigningCertKeyPair, using an injected clock so the
+	// existing CA is seen as having entered its refresh window.
+	notAfter, err := certNotAfter(oldCA)
+	if err != nil {
+		t.Fatalf("failed to read CA not-after annotation: %v", err)
+	}
+	rotatingSigningRotation := SigningRotation{
+		Name:      IngressCASecretName,
+		Namespace: rhsysenggithubiov1beta1.IngressNamespace,
+		Now:       func() time.Time { return notAfter.Add(-time.Minute) },
+	}
+	newCA, op, err := rotatingSigningRotation.EnsureSigningCertKeyPair(ctx, c, relocation, scheme)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if op == controllerutil.OperationResultNone {
+		t.Fatal("test setup invalid: expected the nearing-expiry CA to actually rotate")
+	}
+	if string(newCA.Data[corev1.TLSCertKey]) == string(oldCA.Data[corev1.TLSCertKey]) {
+		t.Fatal("test setup invalid: expected rolled-over CA to differ from the original")
+	}
+
+	// The rolled-over CA secret must retain the previous CA cert, and the existing leaf (still
+	// signed by it) must still chain to it.
+	if len(newCA.Data[previousCACertKey]) == 0 {
+		t.Fatal("expected the previous CA cert to be retained on the rolled-over CA secret")
+	}
+	if !chainsTo(leafCert, newCA) {
+		t.Fatal("expected the existing leaf to still chain to the retained previous CA during the grace period")
+	}
+
+	// Reconciling the leaf against the rolled-over CA must not force a re-issue: the old leaf is
+	// still trusted via the retained previous CA, so there's no need to invalidate it mid-rollover.
+	reconciledLeaf, _, err := targetRotation.EnsureTargetCertKeyPair(ctx, c, relocation, scheme, newCA)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(reconciledLeaf.Data[corev1.TLSCertKey]) != oldLeafCertPEM {
+		t.Fatal("expected the leaf to survive the CA rollover unchanged during the grace period")
+	}
+
+	// The published trust bundle must include both CA certs, so clients trusting either the old
+	// or the new CA keep working during the rollover.
+	if _, err := EnsureIngressCAConfigMap(ctx, c, relocation, scheme, newCA); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Name: IngressCAConfigMapName, Namespace: IngressCAConfigMapNamespace}, cm); err != nil {
+		t.Fatalf("failed to fetch CA configmap: %v", err)
+	}
+	bundle := cm.Data["ca-bundle.crt"]
+	if !strings.Contains(bundle, string(newCA.Data[corev1.TLSCertKey])) {
+		t.Error("expected published CA bundle to contain the current CA cert")
+	}
+	if !strings.Contains(bundle, string(newCA.Data[previousCACertKey])) {
+		t.Error("expected published CA bundle to contain the previous CA cert during the grace period")
+	}
+}
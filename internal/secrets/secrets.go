@@ -0,0 +1,64 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	rhsysenggithubiov1beta1 "github.com/RHsyseng/cluster-relocation-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// SecretCopySettings controls ownership of the source and destination secrets in CopySecret.
+type SecretCopySettings struct {
+	// OwnOriginal, if true, adds a non-controller owner reference to the relocation on the source secret
+	// so that changes to it can be watched, without taking over its lifecycle.
+	OwnOriginal bool
+	// OriginalOwnedByController indicates the original secret is already owned by the ClusterRelocation
+	// controller, e.g. because we generated it ourselves.
+	OriginalOwnedByController bool
+	// OwnDestination, if true, sets the relocation as owner of the destination secret.
+	OwnDestination bool
+	// DestinationOwnedByController indicates the destination secret's owner reference should be a
+	// controller reference rather than a plain owner reference.
+	DestinationOwnedByController bool
+}
+
+// CopySecret copies the data of the secret at (srcName, srcNamespace) into (dstName, dstNamespace),
+// applying ownership according to settings.
+func CopySecret(ctx context.Context, c client.Client, relocation *rhsysenggithubiov1beta1.ClusterRelocation, scheme *runtime.Scheme, srcName, srcNamespace, dstName, dstNamespace string, settings SecretCopySettings) (controllerutil.OperationResult, error) {
+	src := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Name: srcName, Namespace: srcNamespace}, src); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	if settings.OwnOriginal && !settings.OriginalOwnedByController {
+		if err := controllerutil.SetOwnerReference(relocation, src, scheme); err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+		if err := c.Update(ctx, src); err != nil {
+			return controllerutil.OperationResultNone, err
+		}
+	}
+
+	dst := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: dstName, Namespace: dstNamespace}}
+	return controllerutil.CreateOrUpdate(ctx, c, dst, func() error {
+		data, err := NormalizeTLSData(src)
+		if err != nil {
+			return fmt.Errorf("failed to normalize secret %s/%s: %w", src.Namespace, src.Name, err)
+		}
+		dst.Type = corev1.SecretTypeTLS
+		dst.Data = data
+		if settings.OwnDestination {
+			if settings.DestinationOwnedByController {
+				return controllerutil.SetControllerReference(relocation, dst, scheme)
+			}
+			return controllerutil.SetOwnerReference(relocation, dst, scheme)
+		}
+		return nil
+	})
+}